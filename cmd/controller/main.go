@@ -60,6 +60,8 @@ const (
 var (
 	masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	apiTimeout = flag.Duration("api-timeout", 30*time.Second,
+		"The timeout of each request the reconcilers make to the Kubernetes API server, so that a hung API server doesn't block their workers forever. There is no timeout when 0.")
 )
 
 func main() {
@@ -112,13 +114,39 @@ func main() {
 		logger.Fatalf("Error building caching clientset: %v", err)
 	}
 
+	// The clients above back the informers, whose watches are long-running
+	// requests. Only the requests the reconcilers make themselves get a timeout.
+	reconcileCfg := rest.CopyConfig(cfg)
+	reconcileCfg.Timeout = *apiTimeout
+
+	reconcileKubeClient, err := kubernetes.NewForConfig(reconcileCfg)
+	if err != nil {
+		logger.Fatalf("Error building kubernetes clientset: %v", err)
+	}
+
+	reconcileSharedClient, err := sharedclientset.NewForConfig(reconcileCfg)
+	if err != nil {
+		logger.Fatalf("Error building shared clientset: %v", err)
+	}
+
+	reconcileServingClient, err := clientset.NewForConfig(reconcileCfg)
+	if err != nil {
+		logger.Fatalf("Error building serving clientset: %v", err)
+	}
+
+	reconcileCachingClient, err := cachingclientset.NewForConfig(reconcileCfg)
+	if err != nil {
+		logger.Fatalf("Error building caching clientset: %v", err)
+	}
+
 	configMapWatcher := configmap.NewInformedWatcher(kubeClient, system.Namespace)
 
 	opt := reconciler.Options{
-		KubeClientSet:    kubeClient,
-		SharedClientSet:  sharedClient,
-		ServingClientSet: servingClient,
-		CachingClientSet: cachingClient,
+		KubeClientSet:    reconcileKubeClient,
+		SharedClientSet:  reconcileSharedClient,
+		ServingClientSet: reconcileServingClient,
+		CachingClientSet: reconcileCachingClient,
+		// Backs the informers of the pluggable Build resources.
 		DynamicClientSet: dynamicClient,
 		ConfigMapWatcher: configMapWatcher,
 		Logger:           logger,
@@ -142,6 +170,7 @@ func main() {
 	coreServiceInformer := kubeInformerFactory.Core().V1().Services()
 	endpointsInformer := kubeInformerFactory.Core().V1().Endpoints()
 	configMapInformer := kubeInformerFactory.Core().V1().ConfigMaps()
+	nodeInformer := kubeInformerFactory.Core().V1().Nodes()
 	virtualServiceInformer := sharedInformerFactory.Networking().V1alpha3().VirtualServices()
 	imageInformer := cachingInformerFactory.Caching().V1alpha1().Images()
 
@@ -162,6 +191,7 @@ func main() {
 			coreServiceInformer,
 			endpointsInformer,
 			configMapInformer,
+			nodeInformer,
 			buildInformerFactory,
 		),
 		route.NewController(
@@ -219,6 +249,7 @@ func main() {
 		coreServiceInformer.Informer().HasSynced,
 		endpointsInformer.Informer().HasSynced,
 		configMapInformer.Informer().HasSynced,
+		nodeInformer.Informer().HasSynced,
 		virtualServiceInformer.Informer().HasSynced,
 	} {
 		if ok := cache.WaitForCacheSync(stopCh, synced); !ok {
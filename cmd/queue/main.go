@@ -35,7 +35,6 @@ import (
 	"github.com/knative/pkg/websocket"
 	"github.com/knative/serving/cmd/util"
 	activatorutil "github.com/knative/serving/pkg/activator/util"
-	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
 	"github.com/knative/serving/pkg/http/h2c"
 	"github.com/knative/serving/pkg/logging"
@@ -70,6 +69,9 @@ var (
 	servingAutoscaler      string
 	servingAutoscalerPort  int
 	userTargetPort         int
+	queueServingPort       int
+	queueAdminPort         int
+	queueMetricsPort       int
 	containerConcurrency   int
 	revisionTimeoutSeconds int
 	statChan               = make(chan *autoscaler.Stat, statReportingQueueLength)
@@ -96,6 +98,9 @@ func initEnv() {
 	containerConcurrency = util.MustParseIntEnvOrFatal("CONTAINER_CONCURRENCY", logger)
 	revisionTimeoutSeconds = util.MustParseIntEnvOrFatal("REVISION_TIMEOUT_SECONDS", logger)
 	userTargetPort = util.MustParseIntEnvOrFatal("USER_PORT", logger)
+	queueServingPort = util.MustParseIntEnvOrFatal("QUEUE_SERVING_PORT", logger)
+	queueAdminPort = util.MustParseIntEnvOrFatal("QUEUE_ADMIN_PORT", logger)
+	queueMetricsPort = util.MustParseIntEnvOrFatal("QUEUE_METRICS_PORT", logger)
 
 	// TODO(mattmoor): Move this key to be in terms of the KPA.
 	servingRevisionKey = autoscaler.NewMetricKey(servingNamespace, servingRevision)
@@ -298,7 +303,7 @@ func main() {
 	go func() {
 		mux := http.NewServeMux()
 		mux.Handle("/metrics", promExporter)
-		http.ListenAndServe(fmt.Sprintf(":%d", v1alpha1.RequestQueueMetricsPort), mux)
+		http.ListenAndServe(fmt.Sprintf(":%d", queueMetricsPort), mux)
 	}()
 
 	// Open a websocket connection to the autoscaler
@@ -315,13 +320,13 @@ func main() {
 	}, time.Now())
 
 	adminServer := &http.Server{
-		Addr:    fmt.Sprintf(":%d", v1alpha1.RequestQueueAdminPort),
+		Addr:    fmt.Sprintf(":%d", queueAdminPort),
 		Handler: nil,
 	}
 	setupAdminHandlers(adminServer)
 
 	server = h2c.NewServer(
-		fmt.Sprintf(":%d", v1alpha1.RequestQueuePort),
+		fmt.Sprintf(":%d", queueServingPort),
 		queue.TimeToFirstByteTimeoutHandler(http.HandlerFunc(handler), time.Duration(revisionTimeoutSeconds)*time.Second, "request timeout"))
 
 	// An `ErrServerClosed` should not trigger an early exit of
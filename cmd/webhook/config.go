@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/knative/pkg/apis"
+	"github.com/knative/pkg/webhook"
+	"github.com/knative/serving/pkg/apis/config"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// contextValidatable is implemented by the resources whose validation
+// depends on the policies of the config.Config in the context.
+type contextValidatable interface {
+	webhook.GenericCRD
+	ValidateContext(context.Context) *apis.FieldError
+}
+
+// withConfig validates the resource it wraps with the latest policies of
+// the store. The admission controller only calls Validate, which has no
+// context to carry them.
+type withConfig struct {
+	contextValidatable
+	store *config.Store
+}
+
+var _ webhook.GenericCRD = (*withConfig)(nil)
+var _ apis.Immutable = (*withConfig)(nil)
+
+// Validate implements apis.Validatable
+func (w *withConfig) Validate() *apis.FieldError {
+	return w.ValidateContext(w.store.ToContext(context.Background()))
+}
+
+// CheckImmutableFields implements apis.Immutable, for the resources that
+// have immutable fields.
+func (w *withConfig) CheckImmutableFields(og apis.Immutable) *apis.FieldError {
+	current, ok := w.contextValidatable.(apis.Immutable)
+	if !ok {
+		return nil
+	}
+	original, ok := og.(*withConfig)
+	if !ok {
+		return &apis.FieldError{Message: "The provided original was not wrapped with the configuration"}
+	}
+	return current.CheckImmutableFields(original.contextValidatable.(apis.Immutable))
+}
+
+// DeepCopyObject implements runtime.Object
+func (w *withConfig) DeepCopyObject() runtime.Object {
+	return &withConfig{
+		contextValidatable: w.contextValidatable.DeepCopyObject().(contextValidatable),
+		store:              w.store,
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (w *withConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.contextValidatable)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (w *withConfig) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, w.contextValidatable)
+}
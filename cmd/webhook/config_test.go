@@ -0,0 +1,129 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/knative/pkg/apis/duck"
+	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
+	logtesting "github.com/knative/pkg/logging/testing"
+	"github.com/knative/pkg/webhook"
+	"github.com/knative/serving/pkg/apis/config"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/system"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestStore(t *testing.T, data map[string]string) *config.Store {
+	store := config.NewStore(logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace,
+			Name:      config.WebhookConfigName,
+		},
+		Data: data,
+	})
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace,
+			Name:      config.NetworkConfigName,
+		},
+	})
+	return store
+}
+
+func TestWithConfigValidate(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{Name: "valid"},
+		Spec: v1alpha1.RevisionSpec{
+			Container: corev1.Container{Image: "gcr.io/foo/bar:baz"},
+		},
+	}
+
+	if err := (&withConfig{rev, newTestStore(t, nil)}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, wanted nil", err)
+	}
+
+	store := newTestStore(t, map[string]string{"requireImageDigest": "true"})
+	if err := (&withConfig{rev, store}).Validate(); err == nil {
+		t.Error("Validate() = nil, wanted the configured policy to reject the tag")
+	}
+}
+
+func TestWithConfigDecodes(t *testing.T) {
+	store := newTestStore(t, nil)
+	var handler webhook.GenericCRD = &withConfig{&v1alpha1.Revision{}, store}
+
+	// Decode the way the admission controller does.
+	crd := handler.DeepCopyObject().(webhook.GenericCRD)
+	in := `{"metadata": {"name": "hello"}, "spec": {"container": {"image": "busybox"}}}`
+	if err := json.Unmarshal([]byte(in), &crd); err != nil {
+		t.Fatalf("json.Unmarshal() = %v", err)
+	}
+	rev := crd.(*withConfig).contextValidatable.(*v1alpha1.Revision)
+	if got, want := rev.Spec.Container.Image, "busybox"; got != want {
+		t.Errorf("Image = %q, wanted %q", got, want)
+	}
+	if got := handler.(*withConfig).contextValidatable.(*v1alpha1.Revision).Name; got != "" {
+		t.Errorf("Decoding changed the handler, its name is %q", got)
+	}
+
+	out, err := json.Marshal(crd)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	want, _ := json.Marshal(rev)
+	if string(out) != string(want) {
+		t.Errorf("json.Marshal() = %s, wanted %s", out, want)
+	}
+}
+
+func TestWithConfigIsGenerational(t *testing.T) {
+	// The admission controller refuses to run with handlers that aren't.
+	handler := &withConfig{&v1alpha1.Configuration{}, newTestStore(t, nil)}
+	var emptyGen duckv1alpha1.Generation
+	if err := duck.VerifyType(handler.DeepCopyObject(), &emptyGen); err != nil {
+		t.Errorf("VerifyType() = %v", err)
+	}
+}
+
+func TestWithConfigCheckImmutableFields(t *testing.T) {
+	store := newTestStore(t, nil)
+	revision := func(image string) *withConfig {
+		return &withConfig{&v1alpha1.Revision{
+			Spec: v1alpha1.RevisionSpec{
+				Container: corev1.Container{Image: image},
+			},
+		}, store}
+	}
+
+	if err := revision("busybox").CheckImmutableFields(revision("busybox")); err != nil {
+		t.Errorf("CheckImmutableFields() = %v, wanted nil", err)
+	}
+	if err := revision("helloworld").CheckImmutableFields(revision("busybox")); err == nil {
+		t.Error("CheckImmutableFields() = nil, wanted the changed image to be rejected")
+	}
+
+	// Services have no immutable fields.
+	svc := &withConfig{&v1alpha1.Service{}, store}
+	if err := svc.CheckImmutableFields(svc.DeepCopyObject().(*withConfig)); err != nil {
+		t.Errorf("CheckImmutableFields() = %v, wanted nil", err)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/knative/pkg/signals"
 	"github.com/knative/pkg/webhook"
 	kpa "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
+	apisconfig "github.com/knative/serving/pkg/apis/config"
 	net "github.com/knative/serving/pkg/apis/networking/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/logging"
@@ -72,6 +73,9 @@ func main() {
 	// Watch the logging config map and dynamically update logging levels.
 	configMapWatcher := configmap.NewInformedWatcher(kubeClient, system.Namespace)
 	configMapWatcher.Watch(logging.ConfigName, logging.UpdateLevelFromConfigMap(logger, atomicLevel, component))
+	// Watch the policies our resources are validated against.
+	configStore := apisconfig.NewStore(logger.Named("config-store"))
+	configStore.WatchConfigs(configMapWatcher)
 	if err = configMapWatcher.Start(stopCh); err != nil {
 		logger.Fatalf("failed to start configuration manager: %v", err)
 	}
@@ -88,11 +92,11 @@ func main() {
 		Client:  kubeClient,
 		Options: options,
 		Handlers: map[schema.GroupVersionKind]webhook.GenericCRD{
-			v1alpha1.SchemeGroupVersion.WithKind("Revision"):      &v1alpha1.Revision{},
-			v1alpha1.SchemeGroupVersion.WithKind("Configuration"): &v1alpha1.Configuration{},
-			v1alpha1.SchemeGroupVersion.WithKind("Route"):         &v1alpha1.Route{},
-			v1alpha1.SchemeGroupVersion.WithKind("Service"):       &v1alpha1.Service{},
-			kpa.SchemeGroupVersion.WithKind("PodAutoscaler"):      &kpa.PodAutoscaler{},
+			v1alpha1.SchemeGroupVersion.WithKind("Revision"):      &withConfig{&v1alpha1.Revision{}, configStore},
+			v1alpha1.SchemeGroupVersion.WithKind("Configuration"): &withConfig{&v1alpha1.Configuration{}, configStore},
+			v1alpha1.SchemeGroupVersion.WithKind("Route"):         &withConfig{&v1alpha1.Route{}, configStore},
+			v1alpha1.SchemeGroupVersion.WithKind("Service"):       &withConfig{&v1alpha1.Service{}, configStore},
+			kpa.SchemeGroupVersion.WithKind("PodAutoscaler"):      &withConfig{&kpa.PodAutoscaler{}, configStore},
 			net.SchemeGroupVersion.WithKind("ClusterIngress"):     &net.ClusterIngress{},
 		},
 		Logger: logger,
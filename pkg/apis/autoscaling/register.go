@@ -55,6 +55,24 @@ const (
 	//   autoscaling.knative.dev/target: 75   # target 75% cpu utilization
 	TargetAnnotationKey = GroupName + "/target"
 
+	// ScaleDownDisabledAnnotationKey is the annotation to keep a PodAutoscaler
+	// from ever lowering the number of Pods it has provisioned. For example,
+	//   autoscaling.knative.dev/scaleDownDisabled: "true"
+	ScaleDownDisabledAnnotationKey = GroupName + "/scaleDownDisabled"
+
+	// TickIntervalAnnotationKey is the annotation to specify how often the
+	// autoscaler re-evaluates the desired scale of a PodAutoscaler, overriding
+	// the cluster-wide tick-interval. For example,
+	//   autoscaling.knative.dev/tickInterval: 500ms
+	TickIntervalAnnotationKey = GroupName + "/tickInterval"
+
+	// WarmPoolAnnotationKey is the annotation to specify how many pre-started
+	// Pods the PodAutoscaler should keep on top of its desired scale, so that
+	// bursts of traffic don't have to wait for new Pods to start. The value is
+	// capped by the cluster-wide max-warm-pool-size. For example,
+	//   autoscaling.knative.dev/warmPool: "2"
+	WarmPoolAnnotationKey = GroupName + "/warmPool"
+
 	// KPALabelKey is the label key attached to a K8s Service to hint to the KPA
 	// which services/endpoints should trigger reconciles.
 	KPALabelKey = GroupName + "/kpa"
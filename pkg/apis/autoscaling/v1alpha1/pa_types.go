@@ -108,6 +108,11 @@ type PodAutoscalerStatus struct {
 	// state of the world.
 	// +optional
 	Conditions duckv1alpha1.Conditions `json:"conditions,omitempty"`
+
+	// WarmPoolReplicas is the number of pre-started Pods currently being kept
+	// on top of the desired scale.
+	// +optional
+	WarmPoolReplicas int32 `json:"warmPoolReplicas,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -150,6 +155,20 @@ func (pa *PodAutoscaler) ScaleBounds() (min, max int32) {
 	return
 }
 
+// WarmPool returns the warm pool annotation value. The value of 0 means no
+// warm pool was requested.
+func (pa *PodAutoscaler) WarmPool() int32 {
+	return pa.annotationInt32(autoscaling.WarmPoolAnnotationKey)
+}
+
+// ScaleDownDisabled returns whether the PodAutoscaler has opted out of
+// scaling down via the scaleDownDisabled annotation.
+func (pa *PodAutoscaler) ScaleDownDisabled() bool {
+	// no error check: relying on validation
+	disabled, _ := strconv.ParseBool(pa.Annotations[autoscaling.ScaleDownDisabledAnnotationKey])
+	return disabled
+}
+
 func (pa *PodAutoscaler) MetricTarget() (target int32, ok bool) {
 	if s, ok := pa.Annotations[autoscaling.TargetAnnotationKey]; ok {
 		if i, err := strconv.Atoi(s); err == nil {
@@ -159,6 +178,17 @@ func (pa *PodAutoscaler) MetricTarget() (target int32, ok bool) {
 	return 0, false
 }
 
+// TickInterval returns the tick interval annotation value, if the
+// PodAutoscaler has one.
+func (pa *PodAutoscaler) TickInterval() (time.Duration, bool) {
+	if s, ok := pa.Annotations[autoscaling.TickIntervalAnnotationKey]; ok {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
 // IsReady looks at the conditions and if the Status has a condition
 // PodAutoscalerConditionReady returns true if ConditionStatus is True
 func (rs *PodAutoscalerStatus) IsReady() bool {
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/knative/pkg/apis"
@@ -27,8 +28,16 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 )
 
+// Validate makes sure that PodAutoscaler is properly configured, with the
+// default policies.
 func (rt *PodAutoscaler) Validate() *apis.FieldError {
-	return servingv1alpha1.ValidateObjectMetadata(rt.GetObjectMeta()).
+	return rt.ValidateContext(context.Background())
+}
+
+// ValidateContext makes sure that PodAutoscaler is properly configured, with
+// the policies of the config.Config in the context.
+func (rt *PodAutoscaler) ValidateContext(ctx context.Context) *apis.FieldError {
+	return servingv1alpha1.ValidateObjectMetadata(ctx, rt.GetObjectMeta()).
 		ViaField("metadata").
 		Also(rt.Spec.Validate().ViaField("spec")).
 		Also(rt.validateMetric())
@@ -0,0 +1,116 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// NetworkConfigName is the name of the configmap containing all
+	// customizations for networking features. The controller and the
+	// webhook both read the queue-proxy ports from it.
+	NetworkConfigName = "config-network"
+
+	queueServingPortKey = "queueProxy.servingPort"
+	queueAdminPortKey   = "queueProxy.adminPort"
+	queueMetricsPortKey = "queueProxy.metricsPort"
+
+	// DefaultQueueServingPort is the port the queue-proxy receives
+	// requests on, unless configured otherwise.
+	DefaultQueueServingPort = 8012
+
+	// DefaultQueueAdminPort is the port the queue-proxy serves health
+	// checks and lifecycle hooks on, unless configured otherwise.
+	DefaultQueueAdminPort = 8022
+
+	// DefaultQueueMetricsPort is the port the queue-proxy exposes its
+	// metrics on, unless configured otherwise.
+	DefaultQueueMetricsPort = 9090
+)
+
+// NewQueueProxyPortsFromMap creates a QueueProxyPorts from the supplied Map
+func NewQueueProxyPortsFromMap(configMap map[string]string) (*QueueProxyPorts, error) {
+	qp := defaultQueueProxyPorts()
+
+	for _, p := range []struct {
+		key   string
+		field *int
+	}{{
+		key:   queueServingPortKey,
+		field: &qp.Serving,
+	}, {
+		key:   queueAdminPortKey,
+		field: &qp.Admin,
+	}, {
+		key:   queueMetricsPortKey,
+		field: &qp.Metrics,
+	}} {
+		if raw, ok := configMap[p.key]; !ok {
+			// Keep the default.
+		} else if val, err := strconv.Atoi(raw); err != nil {
+			return nil, err
+		} else if val < 1 || val > 65535 {
+			return nil, fmt.Errorf("%s must be between 1 and 65535, was: %d", p.key, val)
+		} else {
+			*p.field = val
+		}
+	}
+
+	if qp.Serving == qp.Admin || qp.Serving == qp.Metrics || qp.Admin == qp.Metrics {
+		return nil, fmt.Errorf("the queue-proxy ports must be distinct, were: %s", qp)
+	}
+	return qp, nil
+}
+
+// NewQueueProxyPortsFromConfigMap creates a QueueProxyPorts from the supplied configMap
+func NewQueueProxyPortsFromConfigMap(config *corev1.ConfigMap) (*QueueProxyPorts, error) {
+	return NewQueueProxyPortsFromMap(config.Data)
+}
+
+// defaultQueueProxyPorts returns the ports used when none are configured.
+func defaultQueueProxyPorts() *QueueProxyPorts {
+	return &QueueProxyPorts{
+		Serving: DefaultQueueServingPort,
+		Admin:   DefaultQueueAdminPort,
+		Metrics: DefaultQueueMetricsPort,
+	}
+}
+
+// QueueProxyPorts are the ports the queue-proxy sidecar of a Revision's
+// pods listens on. User containers can't use them.
+type QueueProxyPorts struct {
+	// Serving receives the requests proxied to the user container.
+	Serving int
+	// Admin serves the health checks and lifecycle hooks.
+	Admin int
+	// Metrics exposes the queue-proxy metrics to Prometheus.
+	Metrics int
+}
+
+// Reserves returns whether the given port is one of the queue-proxy ports.
+func (p *QueueProxyPorts) Reserves(port int) bool {
+	return port == p.Serving || port == p.Admin || port == p.Metrics
+}
+
+// String lists the queue-proxy ports for messages.
+func (p *QueueProxyPorts) String() string {
+	return fmt.Sprintf("%d, %d and %d", p.Serving, p.Admin, p.Metrics)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knative/serving/pkg/system"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/knative/serving/pkg/reconciler/testing"
+)
+
+func TestOurQueueProxyPorts(t *testing.T) {
+	cm := ConfigMapFromTestFile(t, NetworkConfigName)
+
+	got, err := NewQueueProxyPortsFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("NewQueueProxyPortsFromConfigMap() = %v", err)
+	}
+	// The shipped configuration spells out the defaults.
+	if diff := cmp.Diff(defaultQueueProxyPorts(), got); diff != "" {
+		t.Errorf("Unexpected queue-proxy ports (-want, +got): %v", diff)
+	}
+}
+
+func TestQueueProxyPortsConfiguration(t *testing.T) {
+	queueProxyPortsTests := []struct {
+		name      string
+		wantErr   bool
+		wantPorts *QueueProxyPorts
+		data      map[string]string
+	}{{
+		name:      "network configuration with no queue-proxy ports",
+		wantPorts: defaultQueueProxyPorts(),
+	}, {
+		name: "network configuration with queue-proxy ports",
+		wantPorts: &QueueProxyPorts{
+			Serving: 18012,
+			Admin:   18022,
+			Metrics: 19090,
+		},
+		data: map[string]string{
+			queueServingPortKey: "18012",
+			queueAdminPortKey:   "18022",
+			queueMetricsPortKey: "19090",
+		},
+	}, {
+		name: "network configuration with some queue-proxy ports",
+		wantPorts: &QueueProxyPorts{
+			Serving: DefaultQueueServingPort,
+			Admin:   DefaultQueueAdminPort,
+			Metrics: 9091,
+		},
+		data: map[string]string{
+			queueMetricsPortKey: "9091",
+		},
+	}, {
+		name:    "network configuration with invalid queue-proxy port",
+		wantErr: true,
+		data: map[string]string{
+			queueServingPortKey: "http",
+		},
+	}, {
+		name:    "network configuration with out of range queue-proxy port",
+		wantErr: true,
+		data: map[string]string{
+			queueAdminPortKey: "65536",
+		},
+	}, {
+		name:    "network configuration with conflicting queue-proxy ports",
+		wantErr: true,
+		data: map[string]string{
+			queueMetricsPortKey: "8022",
+		},
+	}}
+
+	for _, tt := range queueProxyPortsTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewQueueProxyPortsFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace,
+					Name:      NetworkConfigName,
+				},
+				Data: tt.data,
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewQueueProxyPortsFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.wantPorts, got); diff != "" {
+				t.Errorf("Unexpected queue-proxy ports (-want, +got): %v", diff)
+			}
+		})
+	}
+}
+
+func TestQueueProxyPortsReserves(t *testing.T) {
+	ports := defaultQueueProxyPorts()
+	for _, port := range []int{8012, 8022, 9090} {
+		if !ports.Reserves(port) {
+			t.Errorf("Reserves(%d) = false, wanted true", port)
+		}
+	}
+	if ports.Reserves(8080) {
+		t.Error("Reserves(8080) = true, wanted false")
+	}
+	if got, want := ports.String(), "8012, 8022 and 9090"; got != want {
+		t.Errorf("String() = %q, wanted %q", got, want)
+	}
+}
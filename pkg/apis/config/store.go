@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+
+	"github.com/knative/pkg/configmap"
+)
+
+type cfgKey struct{}
+
+// Config holds the collection of configurations that validation reads.
+// +k8s:deepcopy-gen=false
+type Config struct {
+	Webhook         *Webhook
+	QueueProxyPorts *QueueProxyPorts
+}
+
+// FromContext returns the Config stored in the context, or nil.
+func FromContext(ctx context.Context) *Config {
+	if cfg, ok := ctx.Value(cfgKey{}).(*Config); ok {
+		return cfg
+	}
+	return nil
+}
+
+// FromContextOrDefaults returns the Config stored in the context, or the
+// defaults when there is none, e.g. outside of the webhook.
+func FromContextOrDefaults(ctx context.Context) *Config {
+	if cfg := FromContext(ctx); cfg != nil {
+		return cfg
+	}
+	return &Config{
+		Webhook:         defaultWebhook(),
+		QueueProxyPorts: defaultQueueProxyPorts(),
+	}
+}
+
+// ToContext stores the Config in the context.
+func ToContext(ctx context.Context, c *Config) context.Context {
+	return context.WithValue(ctx, cfgKey{}, c)
+}
+
+// Store is a typed wrapper around configmap.UntypedStore to handle our configmaps.
+// +k8s:deepcopy-gen=false
+type Store struct {
+	*configmap.UntypedStore
+}
+
+// NewStore creates a new store of Configs and optionally calls functions when ConfigMaps are updated.
+func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value interface{})) *Store {
+	store := &Store{
+		UntypedStore: configmap.NewUntypedStore(
+			"webhook",
+			logger,
+			configmap.Constructors{
+				WebhookConfigName: NewWebhookFromConfigMap,
+				NetworkConfigName: NewQueueProxyPortsFromConfigMap,
+			},
+			onAfterStore...,
+		),
+	}
+
+	return store
+}
+
+// ToContext stores the latest Config in the context.
+func (s *Store) ToContext(ctx context.Context) context.Context {
+	return ToContext(ctx, s.Load())
+}
+
+// Load returns a copy of the latest Config.
+func (s *Store) Load() *Config {
+	return &Config{
+		Webhook:         s.UntypedLoad(WebhookConfigName).(*Webhook).DeepCopy(),
+		QueueProxyPorts: s.UntypedLoad(NetworkConfigName).(*QueueProxyPorts).DeepCopy(),
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	logtesting "github.com/knative/pkg/logging/testing"
+
+	. "github.com/knative/serving/pkg/reconciler/testing"
+)
+
+func TestStoreLoadWithContext(t *testing.T) {
+	store := NewStore(logtesting.TestLogger(t))
+
+	webhookConfig := ConfigMapFromTestFile(t, WebhookConfigName)
+	networkConfig := ConfigMapFromTestFile(t, NetworkConfigName)
+	store.OnConfigChanged(webhookConfig)
+	store.OnConfigChanged(networkConfig)
+
+	config := FromContext(store.ToContext(context.Background()))
+
+	t.Run("webhook", func(t *testing.T) {
+		expected, _ := NewWebhookFromConfigMap(webhookConfig)
+		if diff := cmp.Diff(expected, config.Webhook); diff != "" {
+			t.Errorf("Unexpected webhook config (-want, +got): %v", diff)
+		}
+	})
+
+	t.Run("queue-proxy ports", func(t *testing.T) {
+		expected, _ := NewQueueProxyPortsFromConfigMap(networkConfig)
+		if diff := cmp.Diff(expected, config.QueueProxyPorts); diff != "" {
+			t.Errorf("Unexpected queue-proxy ports (-want, +got): %v", diff)
+		}
+	})
+}
+
+func TestStoreImmutableConfig(t *testing.T) {
+	store := NewStore(logtesting.TestLogger(t))
+
+	store.OnConfigChanged(ConfigMapFromTestFile(t, WebhookConfigName))
+	store.OnConfigChanged(ConfigMapFromTestFile(t, NetworkConfigName))
+
+	config := store.Load()
+	config.Webhook.MaxVolumes = 1
+	config.Webhook.PlaceholderImages[0] = "mutated"
+	config.QueueProxyPorts.Serving = 1
+
+	newConfig := store.Load()
+	if newConfig.Webhook.MaxVolumes == 1 || newConfig.Webhook.PlaceholderImages[0] == "mutated" {
+		t.Error("Webhook config is not immutable")
+	}
+	if newConfig.QueueProxyPorts.Serving == 1 {
+		t.Error("Queue-proxy ports are not immutable")
+	}
+}
+
+func TestFromContextOrDefaults(t *testing.T) {
+	if got := FromContext(context.Background()); got != nil {
+		t.Errorf("FromContext() = %v, wanted nil", got)
+	}
+	got := FromContextOrDefaults(context.Background())
+	if diff := cmp.Diff(defaultWebhook(), got.Webhook); diff != "" {
+		t.Errorf("Unexpected default webhook config (-want, +got): %v", diff)
+	}
+	if diff := cmp.Diff(defaultQueueProxyPorts(), got.QueueProxyPorts); diff != "" {
+		t.Errorf("Unexpected default queue-proxy ports (-want, +got): %v", diff)
+	}
+}
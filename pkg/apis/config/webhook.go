@@ -0,0 +1,214 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	networkingv1alpha1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// WebhookConfigName is the name of the configmap containing the
+	// policies our resources are validated against.
+	WebhookConfigName = "config-webhook"
+
+	requireImageDigestKey                 = "requireImageDigest"
+	allowedDigestAlgorithmsKey            = "allowedDigestAlgorithms"
+	allowedRegistriesKey                  = "allowedRegistries"
+	requiredLabelsKey                     = "requiredLabels"
+	singleConcurrencyMaxTimeoutSecondsKey = "singleConcurrencyMaxTimeoutSeconds"
+	maxScaleLimitKey                      = "maxScaleLimit"
+	maxVolumesKey                         = "maxVolumes"
+	allowedExtendedResourcesKey           = "allowedExtendedResources"
+	placeholderImagesKey                  = "placeholderImages"
+	requireReadinessProbeKey              = "requireReadinessProbe"
+	allowedBuildKindsKey                  = "allowedBuildKinds"
+
+	// DefaultMaxScaleLimit is the largest value the minScale and maxScale
+	// annotations may be set to, unless configured otherwise.
+	DefaultMaxScaleLimit = 1000
+
+	// DefaultMaxVolumes is the maximum number of volumes a Revision may
+	// declare, unless configured otherwise.
+	DefaultMaxVolumes = 64
+
+	// DefaultPlaceholderImage is the image value tooling leaves in the spec
+	// for a build to fill in, unless configured otherwise.
+	DefaultPlaceholderImage = "BUILD_PLACEHOLDER"
+
+	// DefaultSingleConcurrencyMaxTimeoutSeconds is the maximum timeoutSeconds
+	// of Revisions with a containerConcurrency of 1, unless configured
+	// otherwise. It is the global maximum.
+	DefaultSingleConcurrencyMaxTimeoutSeconds = int64(networkingv1alpha1.DefaultTimeout / time.Second)
+)
+
+// NewWebhookFromMap creates a Webhook from the supplied Map
+func NewWebhookFromMap(configMap map[string]string) (*Webhook, error) {
+	wc := defaultWebhook()
+
+	if raw, ok := configMap[requireImageDigestKey]; ok {
+		wc.RequireImageDigest = strings.ToLower(raw) == "true"
+	}
+
+	if raw, ok := configMap[requireReadinessProbeKey]; ok {
+		wc.RequireReadinessProbe = strings.ToLower(raw) == "true"
+	}
+
+	if raw, ok := configMap[allowedDigestAlgorithmsKey]; ok {
+		wc.AllowedDigestAlgorithms = toStringList(raw)
+	}
+
+	if raw, ok := configMap[allowedRegistriesKey]; ok {
+		wc.AllowedRegistries = toStringList(raw)
+	}
+
+	if raw, ok := configMap[requiredLabelsKey]; ok {
+		wc.RequiredLabels = toStringList(raw)
+	}
+
+	if raw, ok := configMap[allowedExtendedResourcesKey]; ok {
+		wc.AllowedExtendedResources = toStringList(raw)
+	}
+
+	if raw, ok := configMap[placeholderImagesKey]; ok {
+		wc.PlaceholderImages = toStringList(raw)
+	}
+
+	if raw, ok := configMap[allowedBuildKindsKey]; ok {
+		wc.AllowedBuildKinds = toStringList(raw)
+	}
+
+	if raw, ok := configMap[singleConcurrencyMaxTimeoutSecondsKey]; ok {
+		if val, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, err
+		} else if val <= 0 || val > DefaultSingleConcurrencyMaxTimeoutSeconds {
+			return nil, fmt.Errorf("%s must be between 1 and %d, was: %d",
+				singleConcurrencyMaxTimeoutSecondsKey, DefaultSingleConcurrencyMaxTimeoutSeconds, val)
+		} else {
+			wc.SingleConcurrencyMaxTimeoutSeconds = val
+		}
+	}
+
+	if raw, ok := configMap[maxScaleLimitKey]; ok {
+		if val, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, err
+		} else if val < 0 {
+			return nil, fmt.Errorf("%s must not be negative, was: %d", maxScaleLimitKey, val)
+		} else {
+			wc.MaxScaleLimit = val
+		}
+	}
+
+	if raw, ok := configMap[maxVolumesKey]; ok {
+		if val, err := strconv.Atoi(raw); err != nil {
+			return nil, err
+		} else if val < 0 {
+			return nil, fmt.Errorf("%s must not be negative, was: %d", maxVolumesKey, val)
+		} else {
+			wc.MaxVolumes = val
+		}
+	}
+	return wc, nil
+}
+
+// NewWebhookFromConfigMap creates a Webhook from the supplied configMap
+func NewWebhookFromConfigMap(config *corev1.ConfigMap) (*Webhook, error) {
+	return NewWebhookFromMap(config.Data)
+}
+
+// defaultWebhook returns the policies applied when none are configured.
+func defaultWebhook() *Webhook {
+	return &Webhook{
+		SingleConcurrencyMaxTimeoutSeconds: DefaultSingleConcurrencyMaxTimeoutSeconds,
+		MaxScaleLimit:                      DefaultMaxScaleLimit,
+		MaxVolumes:                         DefaultMaxVolumes,
+		PlaceholderImages:                  []string{DefaultPlaceholderImage},
+	}
+}
+
+// toStringList splits a comma separated list, dropping empty entries.
+func toStringList(arg string) []string {
+	var list []string
+	for _, s := range strings.Split(arg, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// Webhook includes the policies our resources are validated against.
+type Webhook struct {
+	// RequireImageDigest makes validation reject container images that are
+	// not specified by digest. Otherwise tags are accepted, and the Revision
+	// controller resolves them to a digest recorded in the Revision status.
+	RequireImageDigest bool
+
+	// AllowedDigestAlgorithms is the list of digest algorithms, e.g. sha256,
+	// container images specified by digest may use. Any algorithm is
+	// allowed when empty.
+	AllowedDigestAlgorithms []string
+
+	// AllowedRegistries is the list of registries, including any port, that
+	// container images may be pulled from. Any registry is allowed when
+	// empty.
+	AllowedRegistries []string
+
+	// RequiredLabels is the list of label keys every Revision must carry.
+	// No label is required when empty.
+	RequiredLabels []string
+
+	// SingleConcurrencyMaxTimeoutSeconds is the maximum timeoutSeconds
+	// allowed for Revisions that handle a single request at a time, as a
+	// long running request keeps their only slot busy.
+	SingleConcurrencyMaxTimeoutSeconds int64
+
+	// MaxScaleLimit is the largest value the minScale and maxScale
+	// annotations may be set to. There is no limit when zero.
+	MaxScaleLimit int64
+
+	// MaxVolumes is the maximum number of volumes a Revision may declare,
+	// and of volume mounts each of its containers may have, to bound the
+	// complexity of its pods.
+	MaxVolumes int
+
+	// AllowedExtendedResources is the list of resources, besides cpu,
+	// memory and ephemeral-storage, containers may request or be limited
+	// on, e.g. nvidia.com/gpu or hugepages-2Mi. Pods asking for a resource
+	// no node provides stay Pending.
+	AllowedExtendedResources []string
+
+	// PlaceholderImages is the list of image values tooling leaves in the
+	// spec for a build to fill in. They are rejected when the Revision has
+	// no build.
+	PlaceholderImages []string
+
+	// RequireReadinessProbe makes validation reject Revisions whose serving
+	// container has no readiness probe. Without one its pods are marked
+	// ready, and receive traffic, before the app is listening.
+	RequireReadinessProbe bool
+
+	// AllowedBuildKinds is the list of group qualified kinds, e.g.
+	// Build.build.knative.dev, that a buildRef may point to. Any kind is
+	// allowed when empty.
+	AllowedBuildKinds []string
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knative/serving/pkg/system"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/knative/serving/pkg/reconciler/testing"
+)
+
+func TestOurWebhook(t *testing.T) {
+	cm := ConfigMapFromTestFile(t, WebhookConfigName)
+
+	got, err := NewWebhookFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("NewWebhookFromConfigMap() = %v", err)
+	}
+	// The shipped configuration spells out the defaults.
+	if diff := cmp.Diff(defaultWebhook(), got); diff != "" {
+		t.Errorf("Unexpected webhook config (-want, +got): %v", diff)
+	}
+}
+
+func TestWebhookConfiguration(t *testing.T) {
+	webhookConfigTests := []struct {
+		name        string
+		wantErr     bool
+		wantWebhook *Webhook
+		data        map[string]string
+	}{{
+		name:        "webhook configuration with no policies",
+		wantWebhook: defaultWebhook(),
+	}, {
+		name: "webhook configuration with policies",
+		wantWebhook: &Webhook{
+			RequireImageDigest:                 true,
+			AllowedDigestAlgorithms:            []string{"sha256"},
+			AllowedRegistries:                  []string{"gcr.io", "registry:5000"},
+			RequiredLabels:                     []string{"team"},
+			SingleConcurrencyMaxTimeoutSeconds: 30,
+			MaxScaleLimit:                      0,
+			MaxVolumes:                         2,
+			AllowedExtendedResources:           []string{"nvidia.com/gpu"},
+			RequireReadinessProbe:              true,
+			AllowedBuildKinds:                  []string{"Build.build.knative.dev"},
+		},
+		data: map[string]string{
+			requireImageDigestKey:                 "true",
+			allowedDigestAlgorithmsKey:            "sha256",
+			allowedRegistriesKey:                  "gcr.io, registry:5000",
+			requiredLabelsKey:                     "team",
+			singleConcurrencyMaxTimeoutSecondsKey: "30",
+			maxScaleLimitKey:                      "0",
+			maxVolumesKey:                         "2",
+			allowedExtendedResourcesKey:           "nvidia.com/gpu",
+			placeholderImagesKey:                  "",
+			requireReadinessProbeKey:              "True",
+			allowedBuildKindsKey:                  "Build.build.knative.dev",
+		},
+	}, {
+		name:    "webhook configuration with invalid single concurrency timeout",
+		wantErr: true,
+		data: map[string]string{
+			singleConcurrencyMaxTimeoutSecondsKey: "301",
+		},
+	}, {
+		name:    "webhook configuration with negative max scale limit",
+		wantErr: true,
+		data: map[string]string{
+			maxScaleLimitKey: "-1",
+		},
+	}, {
+		name:    "webhook configuration with invalid max volumes",
+		wantErr: true,
+		data: map[string]string{
+			maxVolumesKey: "many",
+		},
+	}}
+
+	for _, tt := range webhookConfigTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewWebhookFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace,
+					Name:      WebhookConfigName,
+				},
+				Data: tt.data,
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewWebhookFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.wantWebhook, got); diff != "" {
+				t.Errorf("Unexpected webhook config (-want, +got): %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was autogenerated by deepcopy-gen. Do not edit it manually!
+
+package config
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueueProxyPorts) DeepCopyInto(out *QueueProxyPorts) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueProxyPorts.
+func (in *QueueProxyPorts) DeepCopy() *QueueProxyPorts {
+	if in == nil {
+		return nil
+	}
+	out := new(QueueProxyPorts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Webhook) DeepCopyInto(out *Webhook) {
+	*out = *in
+	if in.AllowedDigestAlgorithms != nil {
+		in, out := &in.AllowedDigestAlgorithms, &out.AllowedDigestAlgorithms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredLabels != nil {
+		in, out := &in.RequiredLabels, &out.RequiredLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedExtendedResources != nil {
+		in, out := &in.AllowedExtendedResources, &out.AllowedExtendedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PlaceholderImages != nil {
+		in, out := &in.PlaceholderImages, &out.PlaceholderImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedBuildKinds != nil {
+		in, out := &in.AllowedBuildKinds, &out.AllowedBuildKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Webhook.
+func (in *Webhook) DeepCopy() *Webhook {
+	if in == nil {
+		return nil
+	}
+	out := new(Webhook)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -27,6 +27,41 @@ const (
 	// pinned a revision
 	RevisionLastPinnedAnnotationKey = GroupName + "/lastPinned"
 
+	// DebugSidecarAnnotationKey is the annotation key used to request that
+	// the operator-configured debug sidecar be injected into a Revision's pods.
+	DebugSidecarAnnotationKey = GroupName + "/debugSidecar"
+
+	// BuildEntrypointAnnotationKey is the annotation key used to declare that
+	// the image produced by the Revision's build provides the entrypoint, so
+	// the container must not override it with a command.
+	BuildEntrypointAnnotationKey = GroupName + "/buildProvidesEntrypoint"
+
+	// ModelSourceAnnotationKey is the annotation key used to request that the
+	// operator-configured model loader fetches the data at the given URL into
+	// a volume shared with the Revision's container before it starts.
+	ModelSourceAnnotationKey = GroupName + "/modelSource"
+
+	// ActiveDeadlineSecondsAnnotationKey is the annotation key reserved for
+	// bounding how long a Revision's pods may run. It is only accepted on
+	// Revisions marked with JobStyleAnnotationKey, since a serving Revision's
+	// pods are long-lived.
+	ActiveDeadlineSecondsAnnotationKey = GroupName + "/activeDeadlineSeconds"
+
+	// JobStyleAnnotationKey is the annotation key used to mark a Revision as
+	// running a job to completion rather than serving indefinitely.
+	JobStyleAnnotationKey = GroupName + "/jobStyle"
+
+	// RestartPolicyAnnotationKey is the annotation key reserved for requesting
+	// the restart policy of a Revision's pods. Serving Revisions always run
+	// with Always, OnFailure is only accepted on Revisions marked with
+	// JobStyleAnnotationKey.
+	RestartPolicyAnnotationKey = GroupName + "/restartPolicy"
+
+	// ProtocolAnnotationKey is the annotation key used to override the
+	// protocol, "h2c" or "http1", that a Revision's container is assumed to
+	// speak from the name of its serving port.
+	ProtocolAnnotationKey = GroupName + "/protocol"
+
 	// RouteLabelKey is the label key attached to a Configuration indicating by
 	// which Route it is configured as traffic target.
 	// The key can also be attached to ClusterIngress resources to indicate
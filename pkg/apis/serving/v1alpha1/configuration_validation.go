@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
+
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
@@ -24,14 +26,21 @@ import (
 	"github.com/knative/pkg/apis"
 )
 
-// Validate makes sure that Configuration is properly configured.
+// Validate makes sure that Configuration is properly configured, with the
+// default policies.
 func (c *Configuration) Validate() *apis.FieldError {
-	return ValidateObjectMetadata(c.GetObjectMeta()).ViaField("metadata").
-		Also(c.Spec.Validate().ViaField("spec"))
+	return c.ValidateContext(context.Background())
+}
+
+// ValidateContext makes sure that Configuration is properly configured, with
+// the policies of the config.Config in the context.
+func (c *Configuration) ValidateContext(ctx context.Context) *apis.FieldError {
+	return ValidateObjectMetadata(ctx, c.GetObjectMeta()).ViaField("metadata").
+		Also(c.Spec.Validate(ctx).ViaField("spec"))
 }
 
 // Validate makes sure that ConfigurationSpec is properly configured.
-func (cs *ConfigurationSpec) Validate() *apis.FieldError {
+func (cs *ConfigurationSpec) Validate(ctx context.Context) *apis.FieldError {
 	if equality.Semantic.DeepEqual(cs, &ConfigurationSpec{}) {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
@@ -48,5 +57,5 @@ func (cs *ConfigurationSpec) Validate() *apis.FieldError {
 		errs = errs.Also(apis.ErrInvalidValue(err.Error(), "build"))
 	}
 
-	return errs.Also(cs.RevisionTemplate.Validate().ViaField("revisionTemplate"))
+	return errs.Also(cs.RevisionTemplate.Validate(ctx).ViaField("revisionTemplate"))
 }
@@ -17,18 +17,24 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/knative/pkg/apis"
 	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/apis/config"
+	"github.com/knative/serving/pkg/apis/serving"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ValidateObjectMetadata validates that `metadata` stanza of the
 // resources is correct.
-func ValidateObjectMetadata(meta metav1.Object) *apis.FieldError {
+func ValidateObjectMetadata(ctx context.Context, meta metav1.Object) *apis.FieldError {
 	name := meta.GetName()
 
 	if strings.Contains(name, ".") {
@@ -45,7 +51,39 @@ func ValidateObjectMetadata(meta metav1.Object) *apis.FieldError {
 		}
 	}
 
-	if err := validateScaleBoundsAnnotations(meta.GetAnnotations()); err != nil {
+	if err := validateScaleBoundsAnnotations(ctx, meta.GetAnnotations()); err != nil {
+		return err.ViaField("annotations")
+	}
+
+	if err := validateWarmPoolAnnotation(meta.GetAnnotations()); err != nil {
+		return err.ViaField("annotations")
+	}
+
+	if err := validateTargetAnnotation(meta.GetAnnotations()); err != nil {
+		return err.ViaField("annotations")
+	}
+
+	if err := validateScaleDownDisabledAnnotation(meta.GetAnnotations()); err != nil {
+		return err.ViaField("annotations")
+	}
+
+	if err := validateTickIntervalAnnotation(meta.GetAnnotations()); err != nil {
+		return err.ViaField("annotations")
+	}
+
+	if err := validateModelSourceAnnotation(meta.GetAnnotations()); err != nil {
+		return err.ViaField("annotations")
+	}
+
+	if err := validateActiveDeadlineAnnotation(meta.GetAnnotations()); err != nil {
+		return err.ViaField("annotations")
+	}
+
+	if err := validateRestartPolicyAnnotation(meta.GetAnnotations()); err != nil {
+		return err.ViaField("annotations")
+	}
+
+	if err := validateProtocolAnnotation(meta.GetAnnotations()); err != nil {
 		return err.ViaField("annotations")
 	}
 
@@ -67,18 +105,37 @@ func getIntGT0(m map[string]string, k string) (int64, *apis.FieldError) {
 	return 0, nil
 }
 
-func validateScaleBoundsAnnotations(annotations map[string]string) *apis.FieldError {
+// validateScaleLimit bounds the minScale and maxScale annotations, so that a
+// typo cannot request an unreasonable number of pods.
+func validateScaleLimit(ctx context.Context, k string, v int64) *apis.FieldError {
+	if limit := config.FromContextOrDefaults(ctx).Webhook.MaxScaleLimit; limit > 0 && v > limit {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must not exceed %d", k, limit),
+			Paths:   []string{k},
+		}
+	}
+	return nil
+}
+
+func validateScaleBoundsAnnotations(ctx context.Context, annotations map[string]string) *apis.FieldError {
 	if annotations == nil {
 		return nil
 	}
 
+	// Report the problems of both annotations at once, each at its own key.
+	var errs *apis.FieldError
 	min, err := getIntGT0(annotations, autoscaling.MinScaleAnnotationKey)
-	if err != nil {
-		return err
+	if err == nil {
+		err = validateScaleLimit(ctx, autoscaling.MinScaleAnnotationKey, min)
 	}
+	errs = errs.Also(err)
 	max, err := getIntGT0(annotations, autoscaling.MaxScaleAnnotationKey)
-	if err != nil {
-		return err
+	if err == nil {
+		err = validateScaleLimit(ctx, autoscaling.MaxScaleAnnotationKey, max)
+	}
+	errs = errs.Also(err)
+	if errs != nil {
+		return errs
 	}
 
 	if max != 0 && max < min {
@@ -90,3 +147,124 @@ func validateScaleBoundsAnnotations(annotations map[string]string) *apis.FieldEr
 
 	return nil
 }
+
+func validateWarmPoolAnnotation(annotations map[string]string) *apis.FieldError {
+	_, err := getIntGT0(annotations, autoscaling.WarmPoolAnnotationKey)
+	return err
+}
+
+// validateTargetAnnotation ensures the per-pod target of the autoscaler, which
+// otherwise derives from the container concurrency, is a positive integer.
+func validateTargetAnnotation(annotations map[string]string) *apis.FieldError {
+	_, err := getIntGT0(annotations, autoscaling.TargetAnnotationKey)
+	return err
+}
+
+func validateScaleDownDisabledAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[autoscaling.ScaleDownDisabledAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := strconv.ParseBool(v); err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a boolean", autoscaling.ScaleDownDisabledAnnotationKey),
+			Paths:   []string{autoscaling.ScaleDownDisabledAnnotationKey},
+		}
+	}
+	return nil
+}
+
+func validateTickIntervalAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[autoscaling.TickIntervalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a positive duration", autoscaling.TickIntervalAnnotationKey),
+			Paths:   []string{autoscaling.TickIntervalAnnotationKey},
+		}
+	}
+	return nil
+}
+
+// modelSourceSchemes are the URL schemes a model may be loaded from.
+var modelSourceSchemes = []string{"gs", "s3", "http", "https"}
+
+func validateModelSourceAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.ModelSourceAnnotationKey]
+	if !ok {
+		return nil
+	}
+	u, err := url.Parse(v)
+	if err == nil && u.Host != "" {
+		for _, scheme := range modelSourceSchemes {
+			if u.Scheme == scheme {
+				return nil
+			}
+		}
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Invalid %s annotation value: must be a %s URL",
+			serving.ModelSourceAnnotationKey, strings.Join(modelSourceSchemes, ", ")),
+		Paths: []string{serving.ModelSourceAnnotationKey},
+	}
+}
+
+func validateActiveDeadlineAnnotation(annotations map[string]string) *apis.FieldError {
+	if _, ok := annotations[serving.ActiveDeadlineSecondsAnnotationKey]; !ok {
+		return nil
+	}
+	if _, err := getIntGT0(annotations, serving.ActiveDeadlineSecondsAnnotationKey); err != nil {
+		return err
+	}
+	// A deadline would kill a long-lived server, so only job-style Revisions may have one.
+	if strings.ToLower(annotations[serving.JobStyleAnnotationKey]) != "true" {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation: only allowed when %s is \"true\"",
+				serving.ActiveDeadlineSecondsAnnotationKey, serving.JobStyleAnnotationKey),
+			Paths: []string{serving.ActiveDeadlineSecondsAnnotationKey},
+		}
+	}
+	return nil
+}
+
+func validateRestartPolicyAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.RestartPolicyAnnotationKey]
+	if !ok {
+		return nil
+	}
+	switch corev1.RestartPolicy(v) {
+	case corev1.RestartPolicyAlways:
+		return nil
+	case corev1.RestartPolicyOnFailure:
+		// Restarting only on failure would let a server exit for good, so only
+		// job-style Revisions may ask for it.
+		if strings.ToLower(annotations[serving.JobStyleAnnotationKey]) != "true" {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Invalid %s annotation: %s is only allowed when %s is \"true\"",
+					serving.RestartPolicyAnnotationKey, v, serving.JobStyleAnnotationKey),
+				Paths: []string{serving.RestartPolicyAnnotationKey},
+			}
+		}
+		return nil
+	default:
+		return &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be %s or %s",
+				serving.RestartPolicyAnnotationKey, corev1.RestartPolicyAlways, corev1.RestartPolicyOnFailure),
+			Paths: []string{serving.RestartPolicyAnnotationKey},
+		}
+	}
+}
+
+func validateProtocolAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.ProtocolAnnotationKey]
+	if !ok || isProtocolName(v) {
+		return nil
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Invalid %s annotation value: must be %s or %s",
+			serving.ProtocolAnnotationKey, RevisionProtocolH2C, RevisionProtocolHTTP1),
+		Paths: []string{serving.ProtocolAnnotationKey},
+	}
+}
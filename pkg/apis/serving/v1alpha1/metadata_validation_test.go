@@ -17,12 +17,15 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/knative/pkg/apis"
 	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/apis/config"
+	"github.com/knative/serving/pkg/apis/serving"
 )
 
 func TestValidateScaleBoundAnnotations(t *testing.T) {
@@ -78,6 +81,41 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 		name:        "minScale is 2, maxScale is 5",
 		annotations: map[string]string{autoscaling.MinScaleAnnotationKey: "2", autoscaling.MaxScaleAnnotationKey: "5"},
 		expectErr:   nil,
+	}, {
+		name:        "minScale is -1",
+		annotations: map[string]string{autoscaling.MinScaleAnnotationKey: "-1"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer greater than 0", autoscaling.MinScaleAnnotationKey),
+			Paths:   []string{autoscaling.MinScaleAnnotationKey},
+		},
+	}, {
+		name:        "maxScale is at the limit",
+		annotations: map[string]string{autoscaling.MaxScaleAnnotationKey: "1000"},
+		expectErr:   nil,
+	}, {
+		name:        "maxScale is over the limit",
+		annotations: map[string]string{autoscaling.MaxScaleAnnotationKey: "1000000"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must not exceed %d", autoscaling.MaxScaleAnnotationKey, 1000),
+			Paths:   []string{autoscaling.MaxScaleAnnotationKey},
+		},
+	}, {
+		name:        "minScale is over the limit",
+		annotations: map[string]string{autoscaling.MinScaleAnnotationKey: "1001"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must not exceed %d", autoscaling.MinScaleAnnotationKey, 1000),
+			Paths:   []string{autoscaling.MinScaleAnnotationKey},
+		},
+	}, {
+		name:        "minScale is -3, maxScale is over the limit",
+		annotations: map[string]string{autoscaling.MinScaleAnnotationKey: "-3", autoscaling.MaxScaleAnnotationKey: "1000000"},
+		expectErr: (&apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer greater than 0", autoscaling.MinScaleAnnotationKey),
+			Paths:   []string{autoscaling.MinScaleAnnotationKey},
+		}).Also(&apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must not exceed %d", autoscaling.MaxScaleAnnotationKey, 1000),
+			Paths:   []string{autoscaling.MaxScaleAnnotationKey},
+		}),
 	}, {
 		name:        "minScale is 5, maxScale is 2",
 		annotations: map[string]string{autoscaling.MinScaleAnnotationKey: "5", autoscaling.MaxScaleAnnotationKey: "2"},
@@ -89,7 +127,362 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			err := validateScaleBoundsAnnotations(c.annotations)
+			err := validateScaleBoundsAnnotations(context.Background(), c.annotations)
+			if c.expectErr.Error() != err.Error() {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateScaleBoundAnnotationsWithoutLimit(t *testing.T) {
+	ctx := withWebhookConfig(func(wc *config.Webhook) {
+		wc.MaxScaleLimit = 0
+	})
+
+	annotations := map[string]string{autoscaling.MaxScaleAnnotationKey: "1000000"}
+	if err := validateScaleBoundsAnnotations(ctx, annotations); err != nil {
+		t.Errorf("validateScaleBoundsAnnotations() = %v, wanted nil", err)
+	}
+}
+
+func TestValidateTargetAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name:        "nil annotations",
+		annotations: nil,
+		expectErr:   nil,
+	}, {
+		name:        "target is 10",
+		annotations: map[string]string{autoscaling.TargetAnnotationKey: "10"},
+		expectErr:   nil,
+	}, {
+		name:        "target is 0",
+		annotations: map[string]string{autoscaling.TargetAnnotationKey: "0"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer greater than 0", autoscaling.TargetAnnotationKey),
+			Paths:   []string{autoscaling.TargetAnnotationKey},
+		},
+	}, {
+		name:        "target is 1.5",
+		annotations: map[string]string{autoscaling.TargetAnnotationKey: "1.5"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer greater than 0", autoscaling.TargetAnnotationKey),
+			Paths:   []string{autoscaling.TargetAnnotationKey},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateTargetAnnotation(c.annotations)
+			if !reflect.DeepEqual(c.expectErr, err) {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateScaleDownDisabledAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name:        "nil annotations",
+		annotations: nil,
+		expectErr:   nil,
+	}, {
+		name:        "scaleDownDisabled is true",
+		annotations: map[string]string{autoscaling.ScaleDownDisabledAnnotationKey: "true"},
+		expectErr:   nil,
+	}, {
+		name:        "scaleDownDisabled is false",
+		annotations: map[string]string{autoscaling.ScaleDownDisabledAnnotationKey: "false"},
+		expectErr:   nil,
+	}, {
+		name:        "scaleDownDisabled is foo",
+		annotations: map[string]string{autoscaling.ScaleDownDisabledAnnotationKey: "foo"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a boolean", autoscaling.ScaleDownDisabledAnnotationKey),
+			Paths:   []string{autoscaling.ScaleDownDisabledAnnotationKey},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateScaleDownDisabledAnnotation(c.annotations)
+			if !reflect.DeepEqual(c.expectErr, err) {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateTickIntervalAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name:        "nil annotations",
+		annotations: nil,
+		expectErr:   nil,
+	}, {
+		name:        "tickInterval is 500ms",
+		annotations: map[string]string{autoscaling.TickIntervalAnnotationKey: "500ms"},
+		expectErr:   nil,
+	}, {
+		name:        "tickInterval is 0s",
+		annotations: map[string]string{autoscaling.TickIntervalAnnotationKey: "0s"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a positive duration", autoscaling.TickIntervalAnnotationKey),
+			Paths:   []string{autoscaling.TickIntervalAnnotationKey},
+		},
+	}, {
+		name:        "tickInterval is foo",
+		annotations: map[string]string{autoscaling.TickIntervalAnnotationKey: "foo"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be a positive duration", autoscaling.TickIntervalAnnotationKey),
+			Paths:   []string{autoscaling.TickIntervalAnnotationKey},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateTickIntervalAnnotation(c.annotations)
+			if !reflect.DeepEqual(c.expectErr, err) {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateWarmPoolAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name:        "nil annotations",
+		annotations: nil,
+		expectErr:   nil,
+	}, {
+		name:        "warmPool is 2",
+		annotations: map[string]string{autoscaling.WarmPoolAnnotationKey: "2"},
+		expectErr:   nil,
+	}, {
+		name:        "warmPool is 0",
+		annotations: map[string]string{autoscaling.WarmPoolAnnotationKey: "0"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer greater than 0", autoscaling.WarmPoolAnnotationKey),
+			Paths:   []string{autoscaling.WarmPoolAnnotationKey},
+		},
+	}, {
+		name:        "warmPool is foo",
+		annotations: map[string]string{autoscaling.WarmPoolAnnotationKey: "foo"},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer greater than 0", autoscaling.WarmPoolAnnotationKey),
+			Paths:   []string{autoscaling.WarmPoolAnnotationKey},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateWarmPoolAnnotation(c.annotations)
+			if !reflect.DeepEqual(c.expectErr, err) {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateModelSourceAnnotation(t *testing.T) {
+	invalid := &apis.FieldError{
+		Message: fmt.Sprintf("Invalid %s annotation value: must be a gs, s3, http, https URL", serving.ModelSourceAnnotationKey),
+		Paths:   []string{serving.ModelSourceAnnotationKey},
+	}
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name:        "nil annotations",
+		annotations: nil,
+		expectErr:   nil,
+	}, {
+		name:        "gcs model source",
+		annotations: map[string]string{serving.ModelSourceAnnotationKey: "gs://bucket/models/resnet"},
+		expectErr:   nil,
+	}, {
+		name:        "https model source",
+		annotations: map[string]string{serving.ModelSourceAnnotationKey: "https://example.com/model.tar.gz"},
+		expectErr:   nil,
+	}, {
+		name:        "unsupported scheme",
+		annotations: map[string]string{serving.ModelSourceAnnotationKey: "file:///etc/passwd"},
+		expectErr:   invalid,
+	}, {
+		name:        "not a URL",
+		annotations: map[string]string{serving.ModelSourceAnnotationKey: "resnet"},
+		expectErr:   invalid,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateModelSourceAnnotation(c.annotations)
+			if !reflect.DeepEqual(c.expectErr, err) {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateActiveDeadlineAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name:        "nil annotations",
+		annotations: nil,
+		expectErr:   nil,
+	}, {
+		name: "serving revision with a deadline",
+		annotations: map[string]string{
+			serving.ActiveDeadlineSecondsAnnotationKey: "600",
+		},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation: only allowed when %s is \"true\"",
+				serving.ActiveDeadlineSecondsAnnotationKey, serving.JobStyleAnnotationKey),
+			Paths: []string{serving.ActiveDeadlineSecondsAnnotationKey},
+		},
+	}, {
+		name: "job-style revision with a deadline",
+		annotations: map[string]string{
+			serving.ActiveDeadlineSecondsAnnotationKey: "600",
+			serving.JobStyleAnnotationKey:              "true",
+		},
+		expectErr: nil,
+	}, {
+		name: "job-style revision with an invalid deadline",
+		annotations: map[string]string{
+			serving.ActiveDeadlineSecondsAnnotationKey: "0",
+			serving.JobStyleAnnotationKey:              "true",
+		},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be an integer greater than 0", serving.ActiveDeadlineSecondsAnnotationKey),
+			Paths:   []string{serving.ActiveDeadlineSecondsAnnotationKey},
+		},
+	}, {
+		name: "job-style revision without a deadline",
+		annotations: map[string]string{
+			serving.JobStyleAnnotationKey: "true",
+		},
+		expectErr: nil,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateActiveDeadlineAnnotation(c.annotations)
+			if !reflect.DeepEqual(c.expectErr, err) {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateRestartPolicyAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name:        "nil annotations",
+		annotations: nil,
+		expectErr:   nil,
+	}, {
+		name: "serving revision with Always",
+		annotations: map[string]string{
+			serving.RestartPolicyAnnotationKey: "Always",
+		},
+		expectErr: nil,
+	}, {
+		name: "serving revision with OnFailure",
+		annotations: map[string]string{
+			serving.RestartPolicyAnnotationKey: "OnFailure",
+		},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation: OnFailure is only allowed when %s is \"true\"",
+				serving.RestartPolicyAnnotationKey, serving.JobStyleAnnotationKey),
+			Paths: []string{serving.RestartPolicyAnnotationKey},
+		},
+	}, {
+		name: "job-style revision with OnFailure",
+		annotations: map[string]string{
+			serving.RestartPolicyAnnotationKey: "OnFailure",
+			serving.JobStyleAnnotationKey:      "true",
+		},
+		expectErr: nil,
+	}, {
+		name: "revision with Never",
+		annotations: map[string]string{
+			serving.RestartPolicyAnnotationKey: "Never",
+			serving.JobStyleAnnotationKey:      "true",
+		},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be Always or OnFailure", serving.RestartPolicyAnnotationKey),
+			Paths:   []string{serving.RestartPolicyAnnotationKey},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateRestartPolicyAnnotation(c.annotations)
+			if !reflect.DeepEqual(c.expectErr, err) {
+				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateProtocolAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   *apis.FieldError
+	}{{
+		name:        "nil annotations",
+		annotations: nil,
+		expectErr:   nil,
+	}, {
+		name: "h2c",
+		annotations: map[string]string{
+			serving.ProtocolAnnotationKey: "h2c",
+		},
+		expectErr: nil,
+	}, {
+		name: "http1",
+		annotations: map[string]string{
+			serving.ProtocolAnnotationKey: "http1",
+		},
+		expectErr: nil,
+	}, {
+		name: "grpc",
+		annotations: map[string]string{
+			serving.ProtocolAnnotationKey: "grpc",
+		},
+		expectErr: &apis.FieldError{
+			Message: fmt.Sprintf("Invalid %s annotation value: must be h2c or http1", serving.ProtocolAnnotationKey),
+			Paths:   []string{serving.ProtocolAnnotationKey},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateProtocolAnnotation(c.annotations)
 			if !reflect.DeepEqual(c.expectErr, err) {
 				t.Errorf("Expected: '%+v', Got: '%+v'", c.expectErr, err)
 			}
@@ -19,11 +19,13 @@ package v1alpha1
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/knative/pkg/apis"
 	duckv1alpha1 "github.com/knative/pkg/apis/duck/v1alpha1"
 	"github.com/knative/pkg/kmeta"
+	"github.com/knative/serving/pkg/apis/config"
 	"github.com/knative/serving/pkg/apis/serving"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -132,27 +134,108 @@ const (
 	// in queue-proxy container.
 	RequestQueuePortName string = "queue-port"
 
-	// RequestQueuePort specifies the port number to use for http requests
-	// in queue-proxy container.
-	RequestQueuePort = 8012
+	// RequestQueuePort specifies the default port number to use for http
+	// requests in queue-proxy container.
+	RequestQueuePort = config.DefaultQueueServingPort
 
 	// RequestQueueAdminPortName specifies the port name for
 	// health check and lifecyle hooks for queue-proxy.
 	RequestQueueAdminPortName string = "queueadm-port"
 
-	// RequestQueueAdminPort specifies the port number for
+	// RequestQueueAdminPort specifies the default port number for
 	// health check and lifecyle hooks for queue-proxy.
-	RequestQueueAdminPort = 8022
+	RequestQueueAdminPort = config.DefaultQueueAdminPort
 
-	// RequestQueueMetricsPort specifies the port number for metrics emitted
-	// by queue-proxy.
-	RequestQueueMetricsPort = 9090
+	// RequestQueueMetricsPort specifies the default port number for metrics
+	// emitted by queue-proxy.
+	RequestQueueMetricsPort = config.DefaultQueueMetricsPort
 
 	// RequestQueueMetricsPortName specifies the port name to use for metrics
 	// emitted by queue-proxy.
 	RequestQueueMetricsPortName = "queue-metrics"
 )
 
+// RevisionProtocolType is the application protocol spoken by a Revision's
+// container on its serving port.
+type RevisionProtocolType string
+
+const (
+	// RevisionProtocolHTTP1 is HTTP/1.1, the protocol assumed when the serving
+	// port is unnamed.
+	RevisionProtocolHTTP1 RevisionProtocolType = "http1"
+
+	// RevisionProtocolH2C is HTTP/2 without TLS.
+	RevisionProtocolH2C RevisionProtocolType = "h2c"
+)
+
+// isProtocolName returns whether the given port name selects a protocol.
+// https://github.com/knative/serving/blob/master/docs/runtime-contract.md#inbound-network-connectivity
+func isProtocolName(name string) bool {
+	return name == string(RevisionProtocolHTTP1) || name == string(RevisionProtocolH2C)
+}
+
+// ServingPort returns the port of the container that Queue-proxy sends requests
+// to, or nil if the container does not specify one. A lone port is the serving
+// port; among several ports, it is the one named after a protocol.
+func ServingPort(container corev1.Container) *corev1.ContainerPort {
+	if len(container.Ports) == 1 {
+		return &container.Ports[0]
+	}
+	for i, p := range container.Ports {
+		if isProtocolName(p.Name) {
+			return &container.Ports[i]
+		}
+	}
+	return nil
+}
+
+// ProtocolFromContainer returns the protocol the container serves, as
+// declared by the name of its serving port.
+func ProtocolFromContainer(container corev1.Container) RevisionProtocolType {
+	if p := ServingPort(container); p != nil && p.Name == string(RevisionProtocolH2C) {
+		return RevisionProtocolH2C
+	}
+	return RevisionProtocolHTTP1
+}
+
+// GetProtocol returns the protocol the Revision's serving container speaks:
+// the one set with serving.ProtocolAnnotationKey, if any, or else the one
+// declared by the name of its serving port.
+func (r *Revision) GetProtocol() RevisionProtocolType {
+	if p := r.Annotations[serving.ProtocolAnnotationKey]; isProtocolName(p) {
+		return RevisionProtocolType(p)
+	}
+	return ProtocolFromContainer(*r.Spec.ServingContainer())
+}
+
+// ServingContainer returns the container that serves the Revision's traffic:
+// Container, or the one of Containers that declares ports.
+func (rs *RevisionSpec) ServingContainer() *corev1.Container {
+	if len(rs.Containers) == 0 {
+		return &rs.Container
+	}
+	for i, c := range rs.Containers {
+		if len(c.Ports) > 0 {
+			return &rs.Containers[i]
+		}
+	}
+	// Validation ensures this doesn't happen, fall back on the first one.
+	return &rs.Containers[0]
+}
+
+// Sidecars returns the user containers of the Revision other than the
+// serving container.
+func (rs *RevisionSpec) Sidecars() []corev1.Container {
+	serving := rs.ServingContainer()
+	var sidecars []corev1.Container
+	for i, c := range rs.Containers {
+		if &rs.Containers[i] != serving {
+			sidecars = append(sidecars, c)
+		}
+	}
+	return sidecars
+}
+
 // RevisionSpec holds the desired state of the Revision (from the client).
 type RevisionSpec struct {
 	// TODO: Generation does not work correctly with CRD. They are scrubbed
@@ -194,6 +277,12 @@ type RevisionSpec struct {
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 
+	// ImagePullSecrets lists the Secrets, in the Revision's namespace, holding
+	// the credentials used to pull its container images. They are used in
+	// addition to those of its service account.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
 	// BuildName optionally holds the name of the Build responsible for
 	// producing the container image for its Revision.
 	// DEPRECATED: Use BuildRef instead.
@@ -207,12 +296,42 @@ type RevisionSpec struct {
 
 	// Container defines the unit of execution for this Revision.
 	// In the context of a Revision, we disallow a number of the fields of
-	// this Container, including: name, resources, ports, and volumeMounts.
+	// this Container, including: name and lifecycle. Its volumeMounts must
+	// refer to the Revision's Volumes.
 	// TODO(mattmoor): Link to the runtime contract tracked by:
 	// https://github.com/knative/serving/issues/627
 	// +optional
 	Container corev1.Container `json:"container,omitempty"`
 
+	// Volumes are the volumes the containers of this Revision may mount.
+	// Only Secret and ConfigMap volumes are supported.
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// SecurityContext holds the pod-level security attributes of this
+	// Revision. They apply to the containers of the Revision, where their
+	// own securityContext doesn't override them, but not to the containers
+	// Knative Serving runs alongside them. Sysctls are not supported.
+	// +optional
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+
+	// NodeSelector restricts the nodes the pods of this Revision may be
+	// scheduled on to the ones with all of these labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations lets the pods of this Revision be scheduled on nodes
+	// with matching taints, e.g. GPU or spot instance node pools.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Containers defines the units of execution of Revisions that run more
+	// than one container, instead of Container. Exactly one of them declares
+	// ports: it serves the Revision's traffic, and the others run alongside
+	// it as sidecars (e.g. a log shipper).
+	// +optional
+	Containers []corev1.Container `json:"containers,omitempty"`
+
 	// TimeoutSeconds holds the max duration the instance is allowed for responding to a request.
 	// +optional
 	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
@@ -232,6 +351,10 @@ const (
 	RevisionConditionContainerHealthy duckv1alpha1.ConditionType = "ContainerHealthy"
 	// RevisionConditionActive is set when the revision is receiving traffic.
 	RevisionConditionActive duckv1alpha1.ConditionType = "Active"
+	// RevisionConditionImageSizeWithinThreshold is set once the size of the
+	// user container image has been checked against the threshold configured
+	// by the operator. It does not affect whether the revision is ready.
+	RevisionConditionImageSizeWithinThreshold duckv1alpha1.ConditionType = "ImageSizeWithinThreshold"
 )
 
 var revCondSet = duckv1alpha1.NewLivingConditionSet(
@@ -251,6 +374,12 @@ type RevisionStatus struct {
 	// +optional
 	ServiceName string `json:"serviceName,omitempty"`
 
+	// ClusterIP holds the IP address assigned to the Service named by
+	// ServiceName. It is empty until the Service has been created and
+	// assigned an address.
+	// +optional
+	ClusterIP string `json:"clusterIP,omitempty"`
+
 	// Conditions communicates information about ongoing/complete
 	// reconciliation processes that bring the "spec" inline with the observed
 	// state of the world.
@@ -366,6 +495,36 @@ func (rs *RevisionStatus) MarkProgressDeadlineExceeded(message string) {
 	revCondSet.Manage(rs).MarkFalse(RevisionConditionResourcesAvailable, "ProgressDeadlineExceeded", message)
 }
 
+func (rs *RevisionStatus) MarkResourceNotOwned(kind, name string) {
+	revCondSet.Manage(rs).MarkFalse(RevisionConditionResourcesAvailable, "NotOwned",
+		"%s", RevisionResourceNotOwnedMessage(kind, name))
+}
+
+// MarkResourceFailed surfaces that one of the Revision's child resources
+// could not be created or updated, naming it so users needn't grep the
+// controller logs. The Revision is requeued and retries the operation.
+func (rs *RevisionStatus) MarkResourceFailed(kind, name, message string) {
+	revCondSet.Manage(rs).MarkFalse(RevisionConditionResourcesAvailable, "Failed"+kind,
+		"%s", RevisionResourceFailedMessage(kind, name, message))
+}
+
+// MarkInvalidScaleAnnotation surfaces that one of the Revision's scale
+// annotations cannot be parsed, e.g. because the Revision predates its
+// admission validation. The autoscaler ignores such a bound.
+func (rs *RevisionStatus) MarkInvalidScaleAnnotation(key, value string) {
+	revCondSet.Manage(rs).MarkFalse(RevisionConditionResourcesAvailable, "InvalidScaleAnnotation",
+		"%s", RevisionInvalidScaleAnnotationMessage(key, value))
+}
+
+// ClearResourceFailed resets a failure recorded by MarkResourceFailed once
+// all of the child resources have been reconciled successfully.
+func (rs *RevisionStatus) ClearResourceFailed() {
+	c := rs.GetCondition(RevisionConditionResourcesAvailable)
+	if c != nil && c.Status == corev1.ConditionFalse && strings.HasPrefix(c.Reason, "Failed") {
+		revCondSet.Manage(rs).MarkUnknown(RevisionConditionResourcesAvailable, "Deploying", "")
+	}
+}
+
 func (rs *RevisionStatus) MarkContainerHealthy() {
 	revCondSet.Manage(rs).MarkTrue(RevisionConditionContainerHealthy)
 }
@@ -375,6 +534,27 @@ func (rs *RevisionStatus) MarkContainerExiting(exitCode int32, message string) {
 	revCondSet.Manage(rs).MarkFalse(RevisionConditionContainerHealthy, exitCodeString, RevisionContainerExitingMessage(message))
 }
 
+// MarkImagePullFailed surfaces that the Revision's pods are unable to
+// pull the user container's image.
+func (rs *RevisionStatus) MarkImagePullFailed(message string) {
+	revCondSet.Manage(rs).MarkFalse(RevisionConditionContainerHealthy, "ImagePullFailed",
+		"%s", RevisionImagePullFailedMessage(message))
+}
+
+// MarkImageSizeWithinThreshold surfaces that the user container image is no
+// larger than the threshold configured by the operator.
+func (rs *RevisionStatus) MarkImageSizeWithinThreshold() {
+	revCondSet.Manage(rs).MarkTrue(RevisionConditionImageSizeWithinThreshold)
+}
+
+// MarkImageSizeExceeded surfaces that the user container image is larger
+// than the threshold configured by the operator. The Revision may still
+// become ready.
+func (rs *RevisionStatus) MarkImageSizeExceeded(image string, size, threshold int64) {
+	revCondSet.Manage(rs).MarkFalse(RevisionConditionImageSizeWithinThreshold, "ImageSizeExceeded",
+		"%s", RevisionImageSizeExceededMessage(image, size, threshold))
+}
+
 func (rs *RevisionStatus) MarkResourcesAvailable() {
 	revCondSet.Manage(rs).MarkTrue(RevisionConditionResourcesAvailable)
 }
@@ -413,12 +593,43 @@ func RevisionContainerMissingMessage(image string, message string) string {
 	return fmt.Sprintf("Unable to fetch image %q: %s", image, message)
 }
 
+// RevisionResourceNotOwnedMessage constructs the status message if a child
+// resource with the name we derive for it exists but is not controlled by
+// the Revision.
+func RevisionResourceNotOwnedMessage(kind, name string) string {
+	return fmt.Sprintf("There is an existing %s %q that we do not own.", kind, name)
+}
+
+// RevisionResourceFailedMessage constructs the status message if a child
+// resource of the Revision could not be created or updated.
+func RevisionResourceFailedMessage(kind, name, message string) string {
+	return fmt.Sprintf("Failed to reconcile %s %q: %s", kind, name, message)
+}
+
+// RevisionInvalidScaleAnnotationMessage constructs the status message if a
+// scale annotation of the Revision cannot be parsed.
+func RevisionInvalidScaleAnnotationMessage(key, value string) string {
+	return fmt.Sprintf("Annotation %s=%q is not a valid integer", key, value)
+}
+
 // RevisionContainerExitingMessage constructs the status message if a container
 // fails to come up.
 func RevisionContainerExitingMessage(message string) string {
 	return fmt.Sprintf("Container failed with: %s", message)
 }
 
+// RevisionImagePullFailedMessage constructs the status message if the
+// container image cannot be pulled.
+func RevisionImagePullFailedMessage(message string) string {
+	return fmt.Sprintf("Unable to pull image: %s", message)
+}
+
+// RevisionImageSizeExceededMessage constructs the status message if the
+// container image is larger than the configured threshold.
+func RevisionImageSizeExceededMessage(image string, size, threshold int64) string {
+	return fmt.Sprintf("Image %q is %d bytes, which exceeds the threshold of %d bytes", image, size, threshold)
+}
+
 const (
 	AnnotationParseErrorTypeMissing = "Missing"
 	AnnotationParseErrorTypeInvalid = "Invalid"
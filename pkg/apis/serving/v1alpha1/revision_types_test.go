@@ -471,6 +471,32 @@ func TestTypicalFlowWithProgressDeadlineExceeded(t *testing.T) {
 	}
 }
 
+func TestTypicalFlowWithResourceFailed(t *testing.T) {
+	r := &Revision{}
+	r.Status.InitializeConditions()
+	r.Status.MarkDeploying("Deploying")
+
+	r.Status.MarkResourceFailed("Service", "foo-service", "exceeded quota")
+	want := `Failed to reconcile Service "foo-service": exceeded quota`
+	if got := checkConditionFailedRevision(r.Status, RevisionConditionResourcesAvailable, t); got == nil || got.Message != want {
+		t.Errorf("MarkResourceFailed = %v, want %v", got, want)
+	} else if got.Reason != "FailedService" {
+		t.Errorf("MarkResourceFailed = %v, want %v", got, "FailedService")
+	}
+	checkConditionOngoingRevision(r.Status, RevisionConditionContainerHealthy, t)
+	checkConditionFailedRevision(r.Status, RevisionConditionReady, t)
+
+	// Once the children reconcile, the failure is cleared.
+	r.Status.ClearResourceFailed()
+	checkConditionOngoingRevision(r.Status, RevisionConditionResourcesAvailable, t)
+	checkConditionOngoingRevision(r.Status, RevisionConditionReady, t)
+
+	// Other failures are left alone.
+	r.Status.MarkServiceTimeout()
+	r.Status.ClearResourceFailed()
+	checkConditionFailedRevision(r.Status, RevisionConditionResourcesAvailable, t)
+}
+
 func TestTypicalFlowWithContainerMissing(t *testing.T) {
 	r := &Revision{}
 	r.Status.InitializeConditions()
@@ -493,6 +519,23 @@ func TestTypicalFlowWithContainerMissing(t *testing.T) {
 	}
 }
 
+func TestTypicalFlowWithImagePullFailed(t *testing.T) {
+	r := &Revision{}
+	r.Status.InitializeConditions()
+	r.Status.MarkDeploying("Deploying")
+
+	// Messages come from the kubelet and are not format strings.
+	r.Status.MarkImagePullFailed(`pulling "gcr.io/foo%2Fbar": 100% failed`)
+	want := `Unable to pull image: pulling "gcr.io/foo%2Fbar": 100% failed`
+	checkConditionOngoingRevision(r.Status, RevisionConditionResourcesAvailable, t)
+	if got := checkConditionFailedRevision(r.Status, RevisionConditionContainerHealthy, t); got == nil || got.Message != want {
+		t.Errorf("MarkImagePullFailed = %v, want %v", got, want)
+	} else if got.Reason != "ImagePullFailed" {
+		t.Errorf("MarkImagePullFailed = %v, want %v", got, "ImagePullFailed")
+	}
+	checkConditionFailedRevision(r.Status, RevisionConditionReady, t)
+}
+
 func TestTypicalFlowWithSuspendResume(t *testing.T) {
 	r := &Revision{}
 	r.Status.InitializeConditions()
@@ -714,3 +757,142 @@ func TestRevisionGetLastPinned(t *testing.T) {
 		})
 	}
 }
+
+func TestProtocolFromContainer(t *testing.T) {
+	tests := []struct {
+		name  string
+		ports []corev1.ContainerPort
+		want  RevisionProtocolType
+	}{{
+		name: "no ports",
+		want: RevisionProtocolHTTP1,
+	}, {
+		name:  "unnamed port",
+		ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+		want:  RevisionProtocolHTTP1,
+	}, {
+		name:  "http1 port",
+		ports: []corev1.ContainerPort{{Name: "http1", ContainerPort: 8888}},
+		want:  RevisionProtocolHTTP1,
+	}, {
+		name:  "h2c port",
+		ports: []corev1.ContainerPort{{Name: "h2c", ContainerPort: 8888}},
+		want:  RevisionProtocolH2C,
+	}, {
+		name: "h2c serving port among several",
+		ports: []corev1.ContainerPort{{
+			Name:          "metrics",
+			ContainerPort: 9091,
+		}, {
+			Name:          "h2c",
+			ContainerPort: 8888,
+		}},
+		want: RevisionProtocolH2C,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ProtocolFromContainer(corev1.Container{Ports: test.ports})
+			if got != test.want {
+				t.Errorf("ProtocolFromContainer() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRevisionGetProtocol(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		ports       []corev1.ContainerPort
+		want        RevisionProtocolType
+	}{{
+		name: "no override",
+		ports: []corev1.ContainerPort{{
+			Name:          "h2c",
+			ContainerPort: 8888,
+		}},
+		want: RevisionProtocolH2C,
+	}, {
+		name: "h2c override of an http1 port",
+		annotations: map[string]string{
+			serving.ProtocolAnnotationKey: "h2c",
+		},
+		ports: []corev1.ContainerPort{{
+			Name:          "http1",
+			ContainerPort: 8888,
+		}},
+		want: RevisionProtocolH2C,
+	}, {
+		name: "http1 override of an h2c port",
+		annotations: map[string]string{
+			serving.ProtocolAnnotationKey: "http1",
+		},
+		ports: []corev1.ContainerPort{{
+			Name:          "h2c",
+			ContainerPort: 8888,
+		}},
+		want: RevisionProtocolHTTP1,
+	}, {
+		name: "h2c override without ports",
+		annotations: map[string]string{
+			serving.ProtocolAnnotationKey: "h2c",
+		},
+		want: RevisionProtocolH2C,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: test.annotations,
+				},
+				Spec: RevisionSpec{
+					Container: corev1.Container{Ports: test.ports},
+				},
+			}
+			if got := r.GetProtocol(); got != test.want {
+				t.Errorf("GetProtocol() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestServingContainerAndSidecars(t *testing.T) {
+	serving := corev1.Container{
+		Image: "server",
+		Ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+	}
+	shipper := corev1.Container{Image: "log-shipper"}
+	tests := []struct {
+		name         string
+		spec         RevisionSpec
+		wantServing  corev1.Container
+		wantSidecars []corev1.Container
+	}{{
+		name:        "single container",
+		spec:        RevisionSpec{Container: serving},
+		wantServing: serving,
+	}, {
+		name:         "serving container first",
+		spec:         RevisionSpec{Containers: []corev1.Container{serving, shipper}},
+		wantServing:  serving,
+		wantSidecars: []corev1.Container{shipper},
+	}, {
+		name:         "serving container last",
+		spec:         RevisionSpec{Containers: []corev1.Container{shipper, serving}},
+		wantServing:  serving,
+		wantSidecars: []corev1.Container{shipper},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.wantServing, *test.spec.ServingContainer()); diff != "" {
+				t.Errorf("ServingContainer() (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantSidecars, test.spec.Sidecars()); diff != "" {
+				t.Errorf("Sidecars() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
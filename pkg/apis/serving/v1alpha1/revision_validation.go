@@ -17,37 +17,122 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
+	"path"
 	"strconv"
+	"strings"
 
+	"github.com/ghodss/yaml"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/knative/pkg/apis"
 	"github.com/knative/pkg/kmp"
+	"github.com/knative/serving/pkg/apis/config"
 	networkingv1alpha1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
+	"github.com/knative/serving/pkg/apis/serving"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
-// Validate ensures Revision is properly configured.
+// Validate ensures Revision is properly configured, with the default policies.
 func (rt *Revision) Validate() *apis.FieldError {
-	return ValidateObjectMetadata(rt.GetObjectMeta()).ViaField("metadata").
-		Also(rt.Spec.Validate().ViaField("spec"))
+	return rt.ValidateContext(context.Background())
+}
+
+// ValidateContext ensures Revision is properly configured, with the policies
+// of the config.Config in the context.
+func (rt *Revision) ValidateContext(ctx context.Context) *apis.FieldError {
+	return ValidateObjectMetadata(ctx, rt.GetObjectMeta()).ViaField("metadata").
+		Also(validateRequiredLabels(ctx, rt.GetLabels()).ViaField("metadata")).
+		Also(rt.Spec.Validate(ctx).ViaField("spec")).
+		Also(validateEntrypoint(rt.GetAnnotations(), *rt.Spec.ServingContainer()))
+}
+
+// ValidateRevisionBytes parses a Revision from its YAML or JSON encoding and
+// runs the same defaulting and validation as the webhook, so that tooling can
+// check a Revision before submitting it. The policies of the config.Config in
+// the context apply, as they do in the webhook.
+func ValidateRevisionBytes(ctx context.Context, b []byte) *apis.FieldError {
+	rev := &Revision{}
+	if err := yaml.Unmarshal(b, rev); err != nil {
+		return &apis.FieldError{
+			Message: "Failed to parse Revision",
+			Paths:   []string{apis.CurrentField},
+			Details: err.Error(),
+		}
+	}
+	if rev.Kind != "" && rev.Kind != "Revision" {
+		return apis.ErrInvalidValue(rev.Kind, "kind")
+	}
+	rev.SetDefaults()
+	return rev.ValidateContext(ctx)
+}
+
+func validateRequiredLabels(ctx context.Context, labels map[string]string) *apis.FieldError {
+	var missing []string
+	for _, key := range config.FromContextOrDefaults(ctx).Webhook.RequiredLabels {
+		if _, ok := labels[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Missing required labels: %s", strings.Join(missing, ", ")),
+		Paths:   []string{"labels"},
+	}
 }
 
 // Validate ensures RevisionTemplateSpec is properly configured.
-func (rt *RevisionTemplateSpec) Validate() *apis.FieldError {
-	return rt.Spec.Validate().ViaField("spec")
+func (rt *RevisionTemplateSpec) Validate(ctx context.Context) *apis.FieldError {
+	return rt.Spec.Validate(ctx).ViaField("spec").
+		Also(validateEntrypoint(rt.GetAnnotations(), *rt.Spec.ServingContainer()))
+}
+
+// validateEntrypoint rejects containers that set a command when the
+// annotations declare that the build provides the entrypoint.
+func validateEntrypoint(annotations map[string]string, container corev1.Container) *apis.FieldError {
+	v, ok := annotations[serving.BuildEntrypointAnnotationKey]
+	if !ok {
+		return nil
+	}
+	provided, err := strconv.ParseBool(v)
+	if err != nil {
+		return apis.ErrInvalidValue(v, "metadata.annotations."+serving.BuildEntrypointAnnotationKey)
+	}
+	if provided && len(container.Command) > 0 {
+		return &apis.FieldError{
+			Message: "Command must not be set when the build provides the entrypoint",
+			Paths: []string{
+				"metadata.annotations." + serving.BuildEntrypointAnnotationKey,
+				"spec.container.command",
+			},
+		}
+	}
+	return nil
 }
 
 // Validate ensures RevisionSpec is properly configured.
-func (rs *RevisionSpec) Validate() *apis.FieldError {
+func (rs *RevisionSpec) Validate(ctx context.Context) *apis.FieldError {
 	if equality.Semantic.DeepEqual(rs, &RevisionSpec{}) {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
-	errs := validateContainer(rs.Container).ViaField("container").
-		Also(validateBuildRef(rs.BuildRef).ViaField("buildRef"))
+	errs := validateContainers(ctx, rs).
+		Also(validateVolumes(ctx, rs.Volumes).ViaField("volumes")).
+		Also(validatePodSecurityContext(rs.SecurityContext).ViaField("securityContext")).
+		Also(validateNodeSelector(rs.NodeSelector).ViaField("nodeSelector")).
+		Also(validateTolerations(rs.Tolerations).ViaField("tolerations")).
+		Also(validateHugePages(ctx, rs)).
+		Also(validateBuildRef(ctx, rs.BuildRef).ViaField("buildRef")).
+		Also(validateBuiltImage(rs)).
+		Also(validatePlaceholderImage(ctx, rs)).
+		Also(validateReadinessProbeRequired(ctx, rs)).
+		Also(validateImagePullSecrets(rs.ImagePullSecrets).ViaField("imagePullSecrets")).
+		Also(validateServiceAccountName(rs.ServiceAccountName))
 
 	if err := rs.ConcurrencyModel.Validate().ViaField("concurrencyModel"); err != nil {
 		errs = errs.Also(err)
@@ -57,10 +142,56 @@ func (rs *RevisionSpec) Validate() *apis.FieldError {
 
 	if err := validateTimeoutSeconds(rs.TimeoutSeconds); err != nil {
 		errs = errs.Also(err)
+	} else if err := validateSingleConcurrencyTimeoutSeconds(ctx, rs); err != nil {
+		errs = errs.Also(err)
+	}
+	return errs
+}
+
+// validateContainers validates either the single Container of the Revision,
+// or its Containers, of which exactly one must declare the serving ports.
+func validateContainers(ctx context.Context, rs *RevisionSpec) *apis.FieldError {
+	if len(rs.Containers) == 0 {
+		return validateContainer(ctx, rs.Container).
+			Also(validateVolumeReferences(rs.Container.VolumeMounts, rs.Volumes)).
+			ViaField("container")
+	}
+	if !equality.Semantic.DeepEqual(rs.Container, corev1.Container{}) {
+		return apis.ErrMultipleOneOf("container", "containers")
+	}
+	var errs *apis.FieldError
+	serving := 0
+	for i, c := range rs.Containers {
+		errs = errs.Also(validateUserContainer(ctx, c, len(c.Ports) == 0).
+			Also(validateVolumeReferences(c.VolumeMounts, rs.Volumes)).
+			ViaFieldIndex("containers", i))
+		if len(c.Ports) > 0 {
+			serving++
+		}
+	}
+	if serving != 1 {
+		errs = errs.Also(&apis.FieldError{
+			Message: "Exactly one container must declare ports",
+			Paths:   []string{"containers"},
+			Details: fmt.Sprintf("%d containers declare ports", serving),
+		})
 	}
 	return errs
 }
 
+// validateSingleConcurrencyTimeoutSeconds bounds the timeoutSeconds of Revisions
+// that handle a single request at a time, as a long running request keeps their
+// only slot busy.
+func validateSingleConcurrencyTimeoutSeconds(ctx context.Context, rs *RevisionSpec) *apis.FieldError {
+	max := config.FromContextOrDefaults(ctx).Webhook.SingleConcurrencyMaxTimeoutSeconds
+	if rs.ContainerConcurrency == 1 && rs.TimeoutSeconds > max {
+		return apis.ErrOutOfBoundsValue(fmt.Sprintf("%ds", rs.TimeoutSeconds), "0s",
+			fmt.Sprintf("%ds", max),
+			"timeoutSeconds")
+	}
+	return nil
+}
+
 func validateTimeoutSeconds(timeoutSeconds int64) *apis.FieldError {
 	if timeoutSeconds != 0 {
 		if timeoutSeconds > int64(networkingv1alpha1.DefaultTimeout.Seconds()) || timeoutSeconds < 0 {
@@ -111,14 +242,26 @@ func ValidateContainerConcurrency(cc RevisionContainerConcurrencyType, cm Revisi
 	if cc == 1 && cm != RevisionRequestConcurrencyModelSingle && cm != RevisionRequestConcurrencyModelType("") {
 		return apis.ErrMultipleOneOf("containerConcurrency", "concurrencyModel")
 	}
-	if cc > 1 && cm != RevisionRequestConcurrencyModelType("") {
-		return apis.ErrMultipleOneOf("containerConcurrency", "concurrencyModel")
+	// A Multi model is compatible with any bound above one, but a Single
+	// model cannot admit more than one request at a time.
+	if cc > 1 && cm == RevisionRequestConcurrencyModelSingle {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("containerConcurrency must be 1 when concurrencyModel is %s, got %d", cm, cc),
+			Paths:   []string{"containerConcurrency", "concurrencyModel"},
+		}
 	}
 
 	return nil
 }
 
-func validateContainer(container corev1.Container) *apis.FieldError {
+func validateContainer(ctx context.Context, container corev1.Container) *apis.FieldError {
+	return validateUserContainer(ctx, container, false)
+}
+
+// validateUserContainer validates the serving container, or a sidecar. The
+// probes of the serving container are pointed at its port, while sidecars
+// declare no ports, so theirs must name the port they check.
+func validateUserContainer(ctx context.Context, container corev1.Container, sidecar bool) *apis.FieldError {
 	if equality.Semantic.DeepEqual(container, corev1.Container{}) {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
@@ -129,9 +272,6 @@ func validateContainer(container corev1.Container) *apis.FieldError {
 	if container.Name != "" {
 		ignoredFields = append(ignoredFields, "name")
 	}
-	if len(container.VolumeMounts) > 0 {
-		ignoredFields = append(ignoredFields, "volumeMounts")
-	}
 	if container.Lifecycle != nil {
 		ignoredFields = append(ignoredFields, "lifecycle")
 	}
@@ -140,97 +280,584 @@ func validateContainer(container corev1.Container) *apis.FieldError {
 		// Complain about all ignored fields so that user can remove them all at once.
 		errs = errs.Also(apis.ErrDisallowedFields(ignoredFields...))
 	}
-	if err := validateContainerPorts(container.Ports); err != nil {
+	errs = errs.Also(validateVolumeMounts(ctx, container.VolumeMounts))
+	if err := validateContainerPorts(ctx, container.Ports); err != nil {
 		errs = errs.Also(err.ViaField("ports"))
 	}
+	for i, env := range container.Env {
+		errs = errs.Also(validateEnvVar(env).ViaFieldIndex("env", i))
+	}
+	errs = errs.Also(validateResources(ctx, container.Resources).ViaField("resources"))
+	errs = errs.Also(validateSecurityContext(container.SecurityContext).ViaField("securityContext"))
 	// Validate our probes
-	if err := validateProbe(container.ReadinessProbe).ViaField("readinessProbe"); err != nil {
+	if err := validateProbe(container.ReadinessProbe, sidecar).ViaField("readinessProbe"); err != nil {
 		errs = errs.Also(err)
 	}
-	if err := validateProbe(container.LivenessProbe).ViaField("livenessProbe"); err != nil {
+	if err := validateLivenessProbe(container.LivenessProbe, sidecar).ViaField("livenessProbe"); err != nil {
 		errs = errs.Also(err)
 	}
-	if _, err := name.ParseReference(container.Image, name.WeakValidation); err != nil {
+	if err := validateTerminationMessagePath(container.TerminationMessagePath, container.VolumeMounts); err != nil {
+		errs = errs.Also(err.ViaField("terminationMessagePath"))
+	}
+	if isPlaceholderImage(ctx, container.Image) {
+		// Replaced by the build, or rejected by validatePlaceholderImage.
+	} else if ref, err := name.ParseReference(container.Image, name.WeakValidation); err != nil {
 		fe := &apis.FieldError{
 			Message: "Failed to parse image reference",
 			Paths:   []string{"image"},
 			Details: fmt.Sprintf("image: %q, error: %v", container.Image, err),
 		}
 		errs = errs.Also(fe)
+	} else {
+		if err := validateRegistry(ctx, ref); err != nil {
+			errs = errs.Also(err.ViaField("image"))
+		}
+		if err := validateDigest(ctx, ref); err != nil {
+			errs = errs.Also(err.ViaField("image"))
+		}
+		if err := validateDigestAlgorithm(ctx, ref); err != nil {
+			errs = errs.Also(err.ViaField("image"))
+		}
 	}
 	return errs
 }
 
-func validateContainerPorts(ports []corev1.ContainerPort) *apis.FieldError {
-	if len(ports) == 0 {
+func validateSecurityContext(sc *corev1.SecurityContext) *apis.FieldError {
+	if sc == nil {
 		return nil
 	}
+	if sc.Privileged != nil && *sc.Privileged {
+		return &apis.FieldError{
+			Message: "Privileged containers are not allowed",
+			Paths:   []string{"privileged"},
+		}
+	}
+	return nil
+}
+
+// validatePodSecurityContext rejects the attributes of the pod-level
+// security context we don't apply to the Revision's containers.
+// See pkg/reconciler/v1alpha1/revision/resources/deploy.go#applyPodSecurityContext.
+func validatePodSecurityContext(psc *corev1.PodSecurityContext) *apis.FieldError {
+	if psc == nil {
+		return nil
+	}
+	if len(psc.Sysctls) != 0 {
+		return apis.ErrDisallowedFields("sysctls")
+	}
+	return nil
+}
+
+func validateDigest(ctx context.Context, ref name.Reference) *apis.FieldError {
+	if !config.FromContextOrDefaults(ctx).Webhook.RequireImageDigest {
+		return nil
+	}
+	if _, ok := ref.(name.Digest); ok {
+		return nil
+	}
+	return &apis.FieldError{
+		Message: "Image must be specified by digest",
+		Paths:   []string{apis.CurrentField},
+		Details: fmt.Sprintf("tag %q is mutable, use %s@sha256:... instead", ref.Identifier(), ref.Context().Name()),
+	}
+}
+
+func validateDigestAlgorithm(ctx context.Context, ref name.Reference) *apis.FieldError {
+	allowedAlgorithms := config.FromContextOrDefaults(ctx).Webhook.AllowedDigestAlgorithms
+	digest, ok := ref.(name.Digest)
+	if !ok || len(allowedAlgorithms) == 0 {
+		return nil
+	}
+	algorithm := strings.SplitN(digest.DigestStr(), ":", 2)[0]
+	for _, allowed := range allowedAlgorithms {
+		if algorithm == allowed {
+			return nil
+		}
+	}
+	return &apis.FieldError{
+		Message: "Image digest algorithm is not allowed",
+		Paths:   []string{apis.CurrentField},
+		Details: fmt.Sprintf("algorithm %q is not one of %s", algorithm, strings.Join(allowedAlgorithms, ", ")),
+	}
+}
+
+func validateRegistry(ctx context.Context, ref name.Reference) *apis.FieldError {
+	allowedRegistries := config.FromContextOrDefaults(ctx).Webhook.AllowedRegistries
+	if len(allowedRegistries) == 0 {
+		return nil
+	}
+	registry := ref.Context().RegistryStr()
+	for _, allowed := range allowedRegistries {
+		if registry == allowed {
+			return nil
+		}
+	}
+	return &apis.FieldError{
+		Message: "Image registry is not allowed",
+		Paths:   []string{apis.CurrentField},
+		Details: fmt.Sprintf("registry %q is not one of: %s", registry, strings.Join(allowedRegistries, ", ")),
+	}
+}
+
+// reservedMountPaths are the paths at which the Knative Serving controller
+// mounts volumes into the user container.
+// See pkg/reconciler/v1alpha1/revision/resources/deploy.go#makePodSpec.
+var reservedMountPaths = []string{"/var/log", "/var/lib/knative/model"}
+
+// reservedVolumeNames are the names of the volumes the Knative Serving
+// controller adds to the pods.
+// See pkg/reconciler/v1alpha1/revision/resources/deploy.go#makePodSpec.
+var reservedVolumeNames = []string{"varlog", "configmap", "model"}
+
+// pathsOverlap returns whether one of the paths is, or is within, the other.
+func pathsOverlap(a, b string) bool {
+	a, b = path.Clean(a), path.Clean(b)
+	return a == b ||
+		strings.HasPrefix(a, strings.TrimSuffix(b, "/")+"/") ||
+		strings.HasPrefix(b, strings.TrimSuffix(a, "/")+"/")
+}
+
+func validateVolumes(ctx context.Context, volumes []corev1.Volume) *apis.FieldError {
+	if max := config.FromContextOrDefaults(ctx).Webhook.MaxVolumes; len(volumes) > max {
+		return &apis.FieldError{
+			Message: "Too many volumes",
+			Paths:   []string{apis.CurrentField},
+			Details: fmt.Sprintf("%d volumes, at most %d are allowed", len(volumes), max),
+		}
+	}
+	var errs *apis.FieldError
+	names := make(map[string]bool, len(volumes))
+	for i, v := range volumes {
+		errs = errs.Also(validateVolume(v).ViaIndex(i))
+		if names[v.Name] {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Duplicate volume name %q", v.Name),
+				Paths:   []string{"name"},
+			}).ViaIndex(i)
+		}
+		names[v.Name] = true
+	}
+	return errs
+}
+
+func validateVolume(volume corev1.Volume) *apis.FieldError {
+	var errs *apis.FieldError
+	if volume.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("name"))
+	} else if len(validation.IsDNS1123Label(volume.Name)) > 0 {
+		errs = errs.Also(apis.ErrInvalidValue(volume.Name, "name"))
+	}
+	for _, reserved := range reservedVolumeNames {
+		if volume.Name == reserved {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Volume name %q is reserved", volume.Name),
+				Paths:   []string{"name"},
+			})
+		}
+	}
+
+	// Only Secrets and ConfigMaps may be mounted, other sources like
+	// hostPath would give pods access beyond their own data.
+	vs := volume.VolumeSource
+	switch {
+	case vs.Secret != nil && vs.ConfigMap != nil:
+		errs = errs.Also(apis.ErrMultipleOneOf("secret", "configMap"))
+	case vs.Secret == nil && vs.ConfigMap == nil:
+		errs = errs.Also(apis.ErrMissingOneOf("secret", "configMap"))
+	}
+	vs.Secret, vs.ConfigMap = nil, nil
+	if !equality.Semantic.DeepEqual(vs, corev1.VolumeSource{}) {
+		errs = errs.Also(&apis.FieldError{
+			Message: "Only secret and configMap volumes are supported",
+			Paths:   []string{apis.CurrentField},
+		})
+	}
+	return errs
+}
 
+func validateVolumeMounts(ctx context.Context, mounts []corev1.VolumeMount) *apis.FieldError {
+	if max := config.FromContextOrDefaults(ctx).Webhook.MaxVolumes; len(mounts) > max {
+		return &apis.FieldError{
+			Message: "Too many volume mounts",
+			Paths:   []string{"volumeMounts"},
+			Details: fmt.Sprintf("%d volume mounts, at most %d are allowed", len(mounts), max),
+		}
+	}
 	var errs *apis.FieldError
+	for i, m := range mounts {
+		errs = errs.Also(validateVolumeMount(m).ViaFieldIndex("volumeMounts", i))
+	}
+	return errs
+}
+
+func validateVolumeMount(mount corev1.VolumeMount) *apis.FieldError {
+	if mount.Name == "" {
+		return apis.ErrMissingField("name")
+	}
+	if !path.IsAbs(mount.MountPath) {
+		return &apis.FieldError{
+			Message: "Volume mount path must be absolute",
+			Paths:   []string{"mountPath"},
+			Details: fmt.Sprintf("mountPath: %q", mount.MountPath),
+		}
+	}
+	for _, reserved := range reservedMountPaths {
+		if pathsOverlap(mount.MountPath, reserved) {
+			return &apis.FieldError{
+				Message: "Volume mount path must not overlap a reserved path",
+				Paths:   []string{"mountPath"},
+				Details: fmt.Sprintf("mountPath: %q overlaps %q", mount.MountPath, reserved),
+			}
+		}
+	}
+	return nil
+}
+
+// validateVolumeReferences ensures every volume mount refers to one of the
+// Revision's volumes.
+func validateVolumeReferences(mounts []corev1.VolumeMount, volumes []corev1.Volume) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, m := range mounts {
+		found := false
+		for _, v := range volumes {
+			if v.Name == m.Name {
+				found = true
+				break
+			}
+		}
+		if !found && m.Name != "" {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Volume mount refers to undeclared volume %q", m.Name),
+				Paths:   []string{"name"},
+			}).ViaFieldIndex("volumeMounts", i)
+		}
+	}
+	return errs
+}
+
+func validateTerminationMessagePath(p string, mounts []corev1.VolumeMount) *apis.FieldError {
+	if p == "" {
+		return nil
+	}
+	if !path.IsAbs(p) {
+		return &apis.FieldError{
+			Message: "Termination message path must be absolute",
+			Paths:   []string{apis.CurrentField},
+			Details: fmt.Sprintf("terminationMessagePath: %q", p),
+		}
+	}
+	mountPaths := append([]string{}, reservedMountPaths...)
+	for _, m := range mounts {
+		mountPaths = append(mountPaths, m.MountPath)
+	}
+	cleaned := path.Clean(p)
+	for _, mp := range mountPaths {
+		mp = path.Clean(mp)
+		if cleaned == mp || strings.HasPrefix(cleaned, mp+"/") {
+			return &apis.FieldError{
+				Message: "Termination message path must not be within a mounted volume",
+				Paths:   []string{apis.CurrentField},
+				Details: fmt.Sprintf("terminationMessagePath: %q is within %q", p, mp),
+			}
+		}
+	}
+	return nil
+}
+
+func validateContainerPorts(ctx context.Context, ports []corev1.ContainerPort) *apis.FieldError {
+	if len(ports) == 0 {
+		return nil
+	}
 
 	// user can set container port which names "user-port" to define application's port.
 	// Queue-proxy will use it to send requests to application
 	// if user didn't set any port, it will set default port user-port=8080.
-	if len(ports) > 1 {
+	if len(ports) == 1 {
+		userPort := ports[0]
+		errs := validateContainerPort(ctx, userPort)
+		// The port is named "user-port" on the deployment, but a user cannot set an arbitrary
+		// name on a lone port in Configuration.
+		if userPort.Name != "" && !isProtocolName(userPort.Name) {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Port name %v is not allowed", userPort.Name),
+				Paths:   []string{apis.CurrentField},
+				Details: "Name must be empty, or one of: 'h2c', 'http1'",
+			})
+		}
+		return errs
+	}
+
+	// With several ports, exactly one of them must be named as the serving port;
+	// the others (e.g. a metrics port) are exposed on the container as is, so
+	// their names must be valid and unique, and their numbers unique.
+	var errs *apis.FieldError
+	serving := 0
+	names := make(map[string]bool, len(ports))
+	numbers := make(map[int32]bool, len(ports))
+	for i, port := range ports {
+		errs = errs.Also(validateContainerPort(ctx, port).ViaIndex(i))
+		switch {
+		case isProtocolName(port.Name):
+			// Counted below, more than one is reported as such.
+			serving++
+		case port.Name == UserPortName:
+			errs = errs.Also(apis.ErrInvalidValue(port.Name, "name").ViaIndex(i))
+		case port.Name == "":
+			// Unnamed ports are allowed.
+		default:
+			if verrs := validation.IsValidPortName(port.Name); len(verrs) != 0 {
+				fe := apis.ErrInvalidValue(port.Name, "name")
+				fe.Details = strings.Join(verrs, ", ")
+				errs = errs.Also(fe.ViaIndex(i))
+			} else if names[port.Name] {
+				errs = errs.Also((&apis.FieldError{
+					Message: fmt.Sprintf("Duplicate port name %q", port.Name),
+					Paths:   []string{"name"},
+				}).ViaIndex(i))
+			}
+			names[port.Name] = true
+		}
+		if numbers[port.ContainerPort] {
+			errs = errs.Also((&apis.FieldError{
+				Message: fmt.Sprintf("Duplicate container port %d", port.ContainerPort),
+				Paths:   []string{"ContainerPort"},
+			}).ViaIndex(i))
+		}
+		numbers[port.ContainerPort] = true
+	}
+	if serving != 1 {
 		errs = errs.Also(&apis.FieldError{
-			Message: "More than one container port is set",
+			Message: "Exactly one container port must be the serving port",
 			Paths:   []string{apis.CurrentField},
-			Details: "Only a single port is allowed",
+			Details: "When more than one port is set, exactly one must be named 'h2c' or 'http1'",
 		})
 	}
+	return errs
+}
+
+func validateContainerPort(ctx context.Context, port corev1.ContainerPort) *apis.FieldError {
+	var errs *apis.FieldError
 
-	userPort := ports[0]
 	// Only allow empty (defaulting to "TCP") or explicit TCP for protocol
-	if userPort.Protocol != "" && userPort.Protocol != corev1.ProtocolTCP {
-		errs = errs.Also(apis.ErrInvalidValue(string(userPort.Protocol), "Protocol"))
+	if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+		errs = errs.Also(apis.ErrInvalidValue(string(port.Protocol), "Protocol"))
 	}
 
 	// Don't allow HostIP or HostPort to be set
 	var disallowedFields []string
-	if userPort.HostIP != "" {
+	if port.HostIP != "" {
 		disallowedFields = append(disallowedFields, "HostIP")
 
 	}
-	if userPort.HostPort != 0 {
+	if port.HostPort != 0 {
 		disallowedFields = append(disallowedFields, "HostPort")
 	}
 	if len(disallowedFields) != 0 {
 		errs = errs.Also(apis.ErrDisallowedFields(disallowedFields...))
 	}
 
-	// Don't allow userPort to conflict with QueueProxy sidecar
-	if userPort.ContainerPort == RequestQueuePort ||
-		userPort.ContainerPort == RequestQueueAdminPort ||
-		userPort.ContainerPort == RequestQueueMetricsPort {
-		errs = errs.Also(apis.ErrInvalidValue(strconv.Itoa(int(userPort.ContainerPort)), "ContainerPort"))
+	// Don't allow the port to conflict with QueueProxy sidecar
+	queuePorts := config.FromContextOrDefaults(ctx).QueueProxyPorts
+	if queuePorts.Reserves(int(port.ContainerPort)) {
+		fe := apis.ErrInvalidValue(strconv.Itoa(int(port.ContainerPort)), "ContainerPort")
+		fe.Details = fmt.Sprintf("ports %s are reserved for the queue-proxy", queuePorts)
+		errs = errs.Also(fe)
 	}
 
-	if userPort.ContainerPort < 1 || userPort.ContainerPort > 65535 {
-		errs = errs.Also(apis.ErrOutOfBoundsValue(strconv.Itoa(int(userPort.ContainerPort)), "1", "65535", "ContainerPort"))
+	if port.ContainerPort < 1 || port.ContainerPort > 65535 {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(strconv.Itoa(int(port.ContainerPort)), "1", "65535", "ContainerPort"))
 	}
 
-	// The port is named "user-port" on the deployment, but a user cannot set an arbitrary name on the port
-	// in Configuration. The name field is reserved for content-negotiation. Currently 'h2c' and 'http1' are
-	// allowed.
-	// https://github.com/knative/serving/blob/master/docs/runtime-contract.md#inbound-network-connectivity
-	validPortNames := map[string]bool{
-		"h2c":   true,
-		"http1": true,
-		"":      true,
-	}
+	return errs
+}
+
+// reservedEnvVars are the environment variables Knative Serving sets on the
+// user container, see pkg/reconciler/v1alpha1/revision/resources/env_var.go.
+var reservedEnvVars = map[string]bool{
+	"PORT":            true,
+	"K_REVISION":      true,
+	"K_CONFIGURATION": true,
+	"K_SERVICE":       true,
+}
 
-	if !validPortNames[userPort.Name] {
+func validateEnvVar(env corev1.EnvVar) *apis.FieldError {
+	var errs *apis.FieldError
+	if env.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("name"))
+	} else if reservedEnvVars[env.Name] {
 		errs = errs.Also(&apis.FieldError{
-			Message: fmt.Sprintf("Port name %v is not allowed", ports[0].Name),
-			Paths:   []string{apis.CurrentField},
-			Details: "Name must be empty, or one of: 'h2c', 'http1'",
+			Message: fmt.Sprintf("Env var name %s is reserved", env.Name),
+			Paths:   []string{"name"},
 		})
 	}
+	if env.ValueFrom == nil {
+		return errs
+	}
+	if env.Value != "" {
+		return errs.Also(apis.ErrMultipleOneOf("value", "valueFrom"))
+	}
+	return errs.Also(validateEnvVarSource(env.ValueFrom).ViaField("valueFrom"))
+}
+
+func validateEnvVarSource(src *corev1.EnvVarSource) *apis.FieldError {
+	var set []string
+	var errs *apis.FieldError
+	if ref := src.ConfigMapKeyRef; ref != nil {
+		set = append(set, "configMapKeyRef")
+		errs = errs.Also(validateKeySelector(ref.Name, ref.Key).ViaField("configMapKeyRef"))
+	}
+	if ref := src.SecretKeyRef; ref != nil {
+		set = append(set, "secretKeyRef")
+		errs = errs.Also(validateKeySelector(ref.Name, ref.Key).ViaField("secretKeyRef"))
+	}
+	if ref := src.FieldRef; ref != nil {
+		set = append(set, "fieldRef")
+		if ref.FieldPath == "" {
+			errs = errs.Also(apis.ErrMissingField("fieldRef.fieldPath"))
+		}
+	}
+	if ref := src.ResourceFieldRef; ref != nil {
+		set = append(set, "resourceFieldRef")
+		if ref.Resource == "" {
+			errs = errs.Also(apis.ErrMissingField("resourceFieldRef.resource"))
+		}
+	}
+	switch len(set) {
+	case 0:
+		return apis.ErrMissingOneOf("configMapKeyRef", "secretKeyRef", "fieldRef", "resourceFieldRef")
+	case 1:
+		return errs
+	default:
+		return apis.ErrMultipleOneOf(set...)
+	}
+}
+
+func validateKeySelector(name, key string) *apis.FieldError {
+	var missing []string
+	if name == "" {
+		missing = append(missing, "name")
+	}
+	if key == "" {
+		missing = append(missing, "key")
+	}
+	if len(missing) > 0 {
+		return apis.ErrMissingField(missing...)
+	}
+	return nil
+}
+
+// validateBuiltImage rejects a serving container image pinned to a digest
+// when the Revision's image is produced by a build: the build pushes an image
+// with its own digest, which can't be known beforehand.
+func validateBuiltImage(rs *RevisionSpec) *apis.FieldError {
+	if rs.BuildRef == nil && rs.BuildName == "" {
+		return nil
+	}
+	container := rs.ServingContainer()
+	ref, err := name.ParseReference(container.Image, name.WeakValidation)
+	if err != nil {
+		// Reported by validateContainer.
+		return nil
+	}
+	if _, ok := ref.(name.Digest); !ok {
+		return nil
+	}
+	field := "container.image"
+	for i := range rs.Containers {
+		if &rs.Containers[i] == container {
+			field = fmt.Sprintf("containers[%d].image", i)
+		}
+	}
+	return &apis.FieldError{
+		Message: "Image must not be pinned to a digest when it is produced by a build",
+		Paths:   []string{field},
+		Details: fmt.Sprintf("image: %q, the build produces its own digest", container.Image),
+	}
+}
+
+// validateServiceAccountName checks the name the way the Kubernetes API server
+// does for ServiceAccounts, so that the Deployment doesn't fail to create pods.
+func validateServiceAccountName(name string) *apis.FieldError {
+	if name == "" {
+		// The pods run as the namespace's default service account.
+		return nil
+	}
+	if len(validation.IsDNS1123Subdomain(name)) != 0 {
+		return apis.ErrInvalidValue(name, "serviceAccountName")
+	}
+	return nil
+}
 
+func validateImagePullSecrets(secrets []corev1.LocalObjectReference) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, secret := range secrets {
+		if secret.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name").ViaIndex(i))
+		} else if len(validation.IsDNS1123Subdomain(secret.Name)) != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(secret.Name, "name").ViaIndex(i))
+		}
+	}
+	return errs
+}
+
+func validateNodeSelector(selector map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	for k, v := range selector {
+		if verrs := validation.IsQualifiedName(k); len(verrs) != 0 {
+			errs = errs.Also(apis.ErrInvalidKeyName(k, apis.CurrentField, verrs...))
+		} else if len(validation.IsValidLabelValue(v)) != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(k))
+		}
+	}
 	return errs
 }
 
-func validateBuildRef(buildRef *corev1.ObjectReference) *apis.FieldError {
+// validateTolerations checks the operator and effect combinations of the
+// tolerations, as the Kubernetes API server would on the resulting pods.
+func validateTolerations(tolerations []corev1.Toleration) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, t := range tolerations {
+		errs = errs.Also(validateToleration(t).ViaIndex(i))
+	}
+	return errs
+}
+
+func validateToleration(t corev1.Toleration) *apis.FieldError {
+	var errs *apis.FieldError
+	if t.Key != "" {
+		if len(validation.IsQualifiedName(t.Key)) != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(t.Key, "key"))
+		}
+	} else if t.Operator != corev1.TolerationOpExists {
+		// An empty key matches all taints, which only makes sense with Exists.
+		errs = errs.Also(apis.ErrInvalidValue(string(t.Operator), "operator"))
+	}
+	switch t.Operator {
+	case corev1.TolerationOpEqual, "":
+		if len(validation.IsValidLabelValue(t.Value)) != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(t.Value, "value"))
+		}
+	case corev1.TolerationOpExists:
+		if t.Value != "" {
+			errs = errs.Also(apis.ErrDisallowedFields("value"))
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(string(t.Operator), "operator"))
+	}
+	switch t.Effect {
+	case corev1.TaintEffectNoExecute:
+		// tolerationSeconds may delay the eviction.
+	case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, "":
+		if t.TolerationSeconds != nil {
+			errs = errs.Also(apis.ErrDisallowedFields("tolerationSeconds"))
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(string(t.Effect), "effect"))
+	}
+	return errs
+}
+
+func validateBuildRef(ctx context.Context, buildRef *corev1.ObjectReference) *apis.FieldError {
 	if buildRef == nil {
 		return nil
 	}
@@ -240,6 +867,9 @@ func validateBuildRef(buildRef *corev1.ObjectReference) *apis.FieldError {
 	if len(validation.IsCIdentifier(buildRef.Kind)) != 0 {
 		return apis.ErrInvalidValue(buildRef.Kind, "kind")
 	}
+	if err := validateBuildKind(ctx, buildRef); err != nil {
+		return err
+	}
 	if len(validation.IsDNS1123Label(buildRef.Name)) != 0 {
 		return apis.ErrInvalidValue(buildRef.Name, "name")
 	}
@@ -262,24 +892,236 @@ func validateBuildRef(buildRef *corev1.ObjectReference) *apis.FieldError {
 	return nil
 }
 
-func validateProbe(p *corev1.Probe) *apis.FieldError {
+func validateBuildKind(ctx context.Context, buildRef *corev1.ObjectReference) *apis.FieldError {
+	allowedKinds := config.FromContextOrDefaults(ctx).Webhook.AllowedBuildKinds
+	if len(allowedKinds) == 0 {
+		return nil
+	}
+	gk := buildRef.GroupVersionKind().GroupKind()
+	for _, allowed := range allowedKinds {
+		if schema.ParseGroupKind(allowed) == gk {
+			return nil
+		}
+	}
+	err := apis.ErrInvalidValue(buildRef.Kind, "kind")
+	err.Details = fmt.Sprintf("%s is not one of: %s", gk.String(), strings.Join(allowedKinds, ", "))
+	return err
+}
+
+// standardResources are the resources every node provides.
+var standardResources = []corev1.ResourceName{
+	corev1.ResourceCPU,
+	corev1.ResourceMemory,
+	corev1.ResourceEphemeralStorage,
+}
+
+func isAllowedResource(ctx context.Context, name corev1.ResourceName) bool {
+	for _, r := range standardResources {
+		if name == r {
+			return true
+		}
+	}
+	for _, r := range config.FromContextOrDefaults(ctx).Webhook.AllowedExtendedResources {
+		if string(name) == r {
+			return true
+		}
+	}
+	return false
+}
+
+func validateResourceNames(ctx context.Context, field string, resources corev1.ResourceList) *apis.FieldError {
+	var errs *apis.FieldError
+	for name := range resources {
+		switch {
+		case isAllowedResource(ctx, name):
+		case strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix):
+			// Checked by validateHugePages, as it depends on where the
+			// Revision's pods may land.
+		default:
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Resource %q is not allowed", name),
+				Paths:   []string{field + "." + string(name)},
+				Details: "only cpu, memory, ephemeral-storage and the extended resources allowed by the operator may be used",
+			})
+		}
+	}
+	return errs
+}
+
+// validateHugePages rejects hugepages the operator has not allowed, unless the
+// Revision selects or tolerates the nodes its pods run on, presumably ones
+// configured with them. Otherwise pods may land on any node, so hugepages are
+// only usable once the operator has configured them on every node.
+func validateHugePages(ctx context.Context, rs *RevisionSpec) *apis.FieldError {
+	if len(rs.NodeSelector) > 0 || len(rs.Tolerations) > 0 {
+		return nil
+	}
+	if len(rs.Containers) == 0 {
+		return validateHugePageResources(ctx, rs.Container.Resources).ViaField("container", "resources")
+	}
+	var errs *apis.FieldError
+	for i, c := range rs.Containers {
+		errs = errs.Also(validateHugePageResources(ctx, c.Resources).ViaField("resources").ViaFieldIndex("containers", i))
+	}
+	return errs
+}
+
+func validateHugePageResources(ctx context.Context, r corev1.ResourceRequirements) *apis.FieldError {
+	return validateHugePageNames(ctx, "requests", r.Requests).
+		Also(validateHugePageNames(ctx, "limits", r.Limits))
+}
+
+func validateHugePageNames(ctx context.Context, field string, resources corev1.ResourceList) *apis.FieldError {
+	var errs *apis.FieldError
+	for name := range resources {
+		if isAllowedResource(ctx, name) || !strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+			continue
+		}
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("Resource %q is not allowed", name),
+			Paths:   []string{field + "." + string(name)},
+			Details: "hugepages are only available with a nodeSelector or toleration, or once the operator has configured them on the nodes and allowed them",
+		})
+	}
+	return errs
+}
+
+// validateResources rejects resources the operator has not allowed, and
+// limits below the matching request, which Kubernetes would only reject when
+// creating the Pods.
+func validateResources(ctx context.Context, r corev1.ResourceRequirements) *apis.FieldError {
+	errs := validateResourceNames(ctx, "requests", r.Requests).
+		Also(validateResourceNames(ctx, "limits", r.Limits))
+	for name, limit := range r.Limits {
+		if request, ok := r.Requests[name]; ok && limit.Cmp(request) < 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("%s limit must not be below its request", name),
+				Paths:   []string{"limits." + string(name)},
+				Details: fmt.Sprintf("request: %s, limit: %s", request.String(), limit.String()),
+			})
+		}
+	}
+	return errs
+}
+
+func validateProbe(p *corev1.Probe, sidecar bool) *apis.FieldError {
 	if p == nil {
 		return nil
 	}
-	emptyPort := intstr.IntOrString{}
+	var errs *apis.FieldError
 	switch {
 	case p.Handler.HTTPGet != nil:
-		if p.Handler.HTTPGet.Port != emptyPort {
-			return apis.ErrDisallowedFields("httpGet.port")
-		}
+		errs = validateProbePort(p.Handler.HTTPGet.Port, "httpGet.port", sidecar)
 	case p.Handler.TCPSocket != nil:
-		if p.Handler.TCPSocket.Port != emptyPort {
-			return apis.ErrDisallowedFields("tcpSocket.port")
+		errs = validateProbePort(p.Handler.TCPSocket.Port, "tcpSocket.port", sidecar)
+	}
+	return errs.Also(validateProbeTiming(p))
+}
+
+// validateProbePort disallows the port of the serving container's probes,
+// which makePodSpec fills in. Sidecar probes are copied as they are, so they
+// must give a port number: sidecars declare no ports a name could refer to.
+func validateProbePort(port intstr.IntOrString, field string, sidecar bool) *apis.FieldError {
+	switch {
+	case !sidecar:
+		if port != (intstr.IntOrString{}) {
+			return apis.ErrDisallowedFields(field)
 		}
+	case port == (intstr.IntOrString{}):
+		return apis.ErrMissingField(field)
+	case port.Type != intstr.Int:
+		fe := apis.ErrInvalidValue(port.String(), field)
+		fe.Details = "sidecar probes must use a port number"
+		return fe
+	case port.IntVal < 1 || port.IntVal > 65535:
+		return apis.ErrOutOfBoundsValue(port.String(), "1", "65535", field)
 	}
 	return nil
 }
 
+// validateProbeTiming rejects probe timings that Kubernetes would only
+// reject when creating the Pods. A zero value means the field is unset and
+// Kubernetes defaults it, so only negative values are caught here.
+func validateProbeTiming(p *corev1.Probe) *apis.FieldError {
+	var errs *apis.FieldError
+	if p.PeriodSeconds < 0 {
+		errs = errs.Also(&apis.FieldError{
+			Message: "periodSeconds must be at least 1",
+			Paths:   []string{"periodSeconds"},
+			Details: fmt.Sprintf("periodSeconds: %d", p.PeriodSeconds),
+		})
+	}
+	for _, f := range []struct {
+		name  string
+		value int32
+	}{
+		{"initialDelaySeconds", p.InitialDelaySeconds},
+		{"timeoutSeconds", p.TimeoutSeconds},
+		{"successThreshold", p.SuccessThreshold},
+		{"failureThreshold", p.FailureThreshold},
+	} {
+		if f.value < 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("%s must not be negative", f.name),
+				Paths:   []string{f.name},
+				Details: fmt.Sprintf("%s: %d", f.name, f.value),
+			})
+		}
+	}
+	return errs
+}
+
+func validateLivenessProbe(p *corev1.Probe, sidecar bool) *apis.FieldError {
+	if p == nil {
+		return nil
+	}
+	errs := validateProbe(p, sidecar)
+	// Kubernetes rejects liveness probes with a successThreshold other than 1
+	// (0 is defaulted to 1), so catch this before we create the Deployment.
+	if p.SuccessThreshold > 1 {
+		errs = errs.Also(&apis.FieldError{
+			Message: "successThreshold must be 1 for liveness probes",
+			Paths:   []string{"successThreshold"},
+			Details: fmt.Sprintf("successThreshold: %d", p.SuccessThreshold),
+		})
+	}
+	return errs
+}
+
+// immutableAnnotations are the annotations shaping a Revision's pods, like its
+// spec does, so they can't change after the Revision is created either.
+// Autoscaling annotations remain mutable, as does the debug sidecar annotation
+// so that clearing it removes the sidecar again.
+var immutableAnnotations = []string{
+	serving.ActiveDeadlineSecondsAnnotationKey,
+	serving.BuildEntrypointAnnotationKey,
+	serving.JobStyleAnnotationKey,
+	serving.ModelSourceAnnotationKey,
+	serving.ProtocolAnnotationKey,
+	serving.RestartPolicyAnnotationKey,
+}
+
+// immutableSpec returns the fields of the RevisionSpec that can't change after
+// the Revision is created, as they shape its pods. Fields added to RevisionSpec
+// are mutable unless they are copied here too. The concurrency fields are
+// checked on their own by CheckImmutableFields.
+func immutableSpec(rs RevisionSpec) RevisionSpec {
+	return RevisionSpec{
+		Generation:         rs.Generation,
+		ServiceAccountName: rs.ServiceAccountName,
+		ImagePullSecrets:   rs.ImagePullSecrets,
+		BuildName:          rs.BuildName,
+		BuildRef:           rs.BuildRef,
+		Container:          rs.Container,
+		Volumes:            rs.Volumes,
+		SecurityContext:    rs.SecurityContext,
+		NodeSelector:       rs.NodeSelector,
+		Tolerations:        rs.Tolerations,
+		Containers:         rs.Containers,
+		TimeoutSeconds:     rs.TimeoutSeconds,
+	}
+}
+
 // CheckImmutableFields checks the immutable fields are not modified.
 func (current *Revision) CheckImmutableFields(og apis.Immutable) *apis.FieldError {
 	original, ok := og.(*Revision)
@@ -287,19 +1129,101 @@ func (current *Revision) CheckImmutableFields(og apis.Immutable) *apis.FieldErro
 		return &apis.FieldError{Message: "The provided original was not a Revision"}
 	}
 
-	if diff, err := kmp.SafeDiff(original.Spec, current.Spec); err != nil {
-		return &apis.FieldError{
+	// Tooling sometimes tries to patch the concurrency of a Revision in place,
+	// so point at the way to do it instead of only reporting the diff.
+	var errs *apis.FieldError
+	spec := current.Spec
+	if spec.ConcurrencyModel != original.Spec.ConcurrencyModel {
+		errs = errs.Also(&apis.FieldError{
+			Message: "Immutable field changed, create a new Revision to change concurrency",
+			Paths:   []string{"spec.concurrencyModel"},
+			Details: fmt.Sprintf("%q -> %q", original.Spec.ConcurrencyModel, spec.ConcurrencyModel),
+		})
+	}
+	if spec.ContainerConcurrency != original.Spec.ContainerConcurrency {
+		errs = errs.Also(&apis.FieldError{
+			Message: "Immutable field changed, create a new Revision to change concurrency",
+			Paths:   []string{"spec.containerConcurrency"},
+			Details: fmt.Sprintf("%d -> %d", original.Spec.ContainerConcurrency, spec.ContainerConcurrency),
+		})
+	}
+	for _, key := range immutableAnnotations {
+		if oldValue, newValue := original.Annotations[key], current.Annotations[key]; oldValue != newValue {
+			errs = errs.Also(&apis.FieldError{
+				Message: "Immutable annotation changed, create a new Revision to change it",
+				Paths:   []string{"metadata.annotations." + key},
+				Details: fmt.Sprintf("%q -> %q", oldValue, newValue),
+			})
+		}
+	}
+
+	if diff, err := kmp.SafeDiff(immutableSpec(original.Spec), immutableSpec(spec)); err != nil {
+		return errs.Also(&apis.FieldError{
 			Message: "Failed to diff Revision",
 			Paths:   []string{"spec"},
 			Details: err.Error(),
-		}
+		})
 	} else if diff != "" {
-		return &apis.FieldError{
+		return errs.Also(&apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
 			Details: diff,
+		})
+	}
+
+	return errs
+}
+
+// validatePlaceholderImage rejects container images that are still one of
+// the configured placeholder images and that no build will replace, since the
+// pod would never start. A build only replaces the serving container image.
+func validatePlaceholderImage(ctx context.Context, rs *RevisionSpec) *apis.FieldError {
+	built := rs.BuildRef != nil || rs.BuildName != ""
+	serving := rs.ServingContainer()
+	placeholder := func(image string) *apis.FieldError {
+		return &apis.FieldError{
+			Message: "Image is a placeholder, set a real image or a buildRef",
+			Paths:   []string{"image"},
+			Details: fmt.Sprintf("image: %q", image),
+		}
+	}
+	if len(rs.Containers) == 0 {
+		if built || !isPlaceholderImage(ctx, rs.Container.Image) {
+			return nil
 		}
+		return placeholder(rs.Container.Image).ViaField("container")
 	}
+	var errs *apis.FieldError
+	for i := range rs.Containers {
+		if built && &rs.Containers[i] == serving {
+			continue
+		}
+		if isPlaceholderImage(ctx, rs.Containers[i].Image) {
+			errs = errs.Also(placeholder(rs.Containers[i].Image).ViaFieldIndex("containers", i))
+		}
+	}
+	return errs
+}
 
-	return nil
+func isPlaceholderImage(ctx context.Context, image string) bool {
+	for _, placeholder := range config.FromContextOrDefaults(ctx).Webhook.PlaceholderImages {
+		if image == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+func validateReadinessProbeRequired(ctx context.Context, rs *RevisionSpec) *apis.FieldError {
+	container := rs.ServingContainer()
+	if !config.FromContextOrDefaults(ctx).Webhook.RequireReadinessProbe || container.ReadinessProbe != nil {
+		return nil
+	}
+	field := "container"
+	for i := range rs.Containers {
+		if &rs.Containers[i] == container {
+			field = fmt.Sprintf("containers[%d]", i)
+		}
+	}
+	return apis.ErrMissingField("readinessProbe").ViaField(field)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -25,14 +26,38 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/knative/pkg/apis"
 	"github.com/knative/serving/pkg/apis/autoscaling"
+	"github.com/knative/serving/pkg/apis/config"
 	netv1alpha1 "github.com/knative/serving/pkg/apis/networking/v1alpha1"
+	"github.com/knative/serving/pkg/apis/serving"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+// withWebhookConfig returns a context carrying the default policies, as
+// changed by f.
+func withWebhookConfig(f func(*config.Webhook)) context.Context {
+	cfg := config.FromContextOrDefaults(context.Background())
+	f(cfg.Webhook)
+	return config.ToContext(context.Background(), cfg)
+}
+
+func reservedPortError(port, path string) *apis.FieldError {
+	fe := apis.ErrInvalidValue(port, path)
+	fe.Details = "ports 8012, 8022 and 9090 are reserved for the queue-proxy"
+	return fe
+}
+
+func invalidPortNameError(name, path string) *apis.FieldError {
+	fe := apis.ErrInvalidValue(name, path)
+	fe.Details = strings.Join(validation.IsValidPortName(name), ", ")
+	return fe
+}
+
 func TestContainerValidation(t *testing.T) {
+	yes := true
 	tests := []struct {
 		name string
 		c    corev1.Container
@@ -102,6 +127,45 @@ func TestContainerValidation(t *testing.T) {
 			}},
 		},
 		want: nil,
+	}, {
+		name: "has a serving port and a metrics port",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "h2c",
+				ContainerPort: 8080,
+			}, {
+				Name:          "metrics",
+				ContainerPort: 8181,
+			}},
+		},
+		want: nil,
+	}, {
+		name: "has an additional port that conflicts with queue proxy metrics",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "http1",
+				ContainerPort: 8080,
+			}, {
+				Name:          "metrics",
+				ContainerPort: 9090,
+			}},
+		},
+		want: reservedPortError("9090", "ports[1].ContainerPort"),
+	}, {
+		name: "has an additional port with the reserved name",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "http1",
+				ContainerPort: 8080,
+			}, {
+				Name:          "user-port",
+				ContainerPort: 8181,
+			}},
+		},
+		want: apis.ErrInvalidValue("user-port", "ports[1].name"),
 	}, {
 		name: "has more than one ports with valid names",
 		c: corev1.Container{
@@ -115,9 +179,70 @@ func TestContainerValidation(t *testing.T) {
 			}},
 		},
 		want: &apis.FieldError{
-			Message: "More than one container port is set",
+			Message: "Exactly one container port must be the serving port",
 			Paths:   []string{"ports"},
-			Details: "Only a single port is allowed",
+			Details: "When more than one port is set, exactly one must be named 'h2c' or 'http1'",
+		},
+	}, {
+		name: "has additional ports with the same name",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "http1",
+				ContainerPort: 8080,
+			}, {
+				Name:          "metrics",
+				ContainerPort: 8181,
+			}, {
+				Name:          "metrics",
+				ContainerPort: 8282,
+			}},
+		},
+		want: &apis.FieldError{
+			Message: `Duplicate port name "metrics"`,
+			Paths:   []string{"ports[2].name"},
+		},
+	}, {
+		name: "has an additional port with an invalid name",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "http1",
+				ContainerPort: 8080,
+			}, {
+				Name:          "Metrics_1",
+				ContainerPort: 8181,
+			}},
+		},
+		want: invalidPortNameError("Metrics_1", "ports[1].name"),
+	}, {
+		name: "has an additional port with a too long name",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "http1",
+				ContainerPort: 8080,
+			}, {
+				Name:          "prometheus-metrics",
+				ContainerPort: 8181,
+			}},
+		},
+		want: invalidPortNameError("prometheus-metrics", "ports[1].name"),
+	}, {
+		name: "has an additional port reusing the serving port",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "http1",
+				ContainerPort: 8080,
+			}, {
+				Name:          "metrics",
+				ContainerPort: 8080,
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "Duplicate container port 8080",
+			Paths:   []string{"ports[1].ContainerPort"},
 		},
 	}, {
 		name: "has container port value too large",
@@ -146,9 +271,9 @@ func TestContainerValidation(t *testing.T) {
 			}},
 		},
 		want: &apis.FieldError{
-			Message: "More than one container port is set",
+			Message: "Exactly one container port must be the serving port",
 			Paths:   []string{"ports"},
-			Details: "Only a single port is allowed",
+			Details: "When more than one port is set, exactly one must be named 'h2c' or 'http1'",
 		},
 	}, {
 		name: "has tcp protocol",
@@ -198,7 +323,7 @@ func TestContainerValidation(t *testing.T) {
 				ContainerPort: 8022,
 			}},
 		},
-		want: apis.ErrInvalidValue("8022", "ports.ContainerPort"),
+		want: reservedPortError("8022", "ports.ContainerPort"),
 	}, {
 		name: "port conflicts with queue proxy",
 		c: corev1.Container{
@@ -207,7 +332,7 @@ func TestContainerValidation(t *testing.T) {
 				ContainerPort: 8012,
 			}},
 		},
-		want: apis.ErrInvalidValue("8012", "ports.ContainerPort"),
+		want: reservedPortError("8012", "ports.ContainerPort"),
 	}, {
 		name: "port conflicts with queue proxy metrics",
 		c: corev1.Container{
@@ -216,7 +341,7 @@ func TestContainerValidation(t *testing.T) {
 				ContainerPort: 9090,
 			}},
 		},
-		want: apis.ErrInvalidValue("9090", "ports.ContainerPort"),
+		want: reservedPortError("9090", "ports.ContainerPort"),
 	}, {
 		name: "has invalid port name",
 		c: corev1.Container{
@@ -231,6 +356,88 @@ func TestContainerValidation(t *testing.T) {
 			Paths:   []string{"ports"},
 			Details: "Name must be empty, or one of: 'h2c', 'http1'",
 		},
+	}, {
+		name: "has valid env",
+		c: corev1.Container{
+			Image: "foo",
+			Env: []corev1.EnvVar{{
+				Name:  "FOO",
+				Value: "bar",
+			}, {
+				Name: "BAZ",
+				ValueFrom: &corev1.EnvVarSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "cm"},
+						Key:                  "baz",
+					},
+				},
+			}},
+		},
+		want: nil,
+	}, {
+		name: "has reserved env var name",
+		c: corev1.Container{
+			Image: "foo",
+			Env: []corev1.EnvVar{{
+				Name:  "FOO",
+				Value: "bar",
+			}, {
+				Name:  "PORT",
+				Value: "8888",
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "Env var name PORT is reserved",
+			Paths:   []string{"env[1].name"},
+		},
+	}, {
+		name: "has env var without a name",
+		c: corev1.Container{
+			Image: "foo",
+			Env: []corev1.EnvVar{{
+				Value: "bar",
+			}},
+		},
+		want: apis.ErrMissingField("env[0].name"),
+	}, {
+		name: "has env var with both value and valueFrom",
+		c: corev1.Container{
+			Image: "foo",
+			Env: []corev1.EnvVar{{
+				Name:  "FOO",
+				Value: "bar",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+				},
+			}},
+		},
+		want: apis.ErrMultipleOneOf("env[0].value", "env[0].valueFrom"),
+	}, {
+		name: "has env var referencing a secret without a key",
+		c: corev1.Container{
+			Image: "foo",
+			Env: []corev1.EnvVar{{
+				Name: "FOO",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "secret"},
+					},
+				},
+			}},
+		},
+		want: apis.ErrMissingField("env[0].valueFrom.secretKeyRef.key"),
+	}, {
+		name: "has env var with an empty valueFrom",
+		c: corev1.Container{
+			Image: "foo",
+			Env: []corev1.EnvVar{{
+				Name:      "FOO",
+				ValueFrom: &corev1.EnvVarSource{},
+			}},
+		},
+		want: apis.ErrMissingOneOf(
+			"env[0].valueFrom.configMapKeyRef", "env[0].valueFrom.fieldRef",
+			"env[0].valueFrom.resourceFieldRef", "env[0].valueFrom.secretKeyRef"),
 	}, {
 		name: "has volumeMounts",
 		c: corev1.Container{
@@ -240,7 +447,68 @@ func TestContainerValidation(t *testing.T) {
 				Name:      "name",
 			}},
 		},
-		want: apis.ErrDisallowedFields("volumeMounts"),
+		want: &apis.FieldError{
+			Message: "Volume mount path must be absolute",
+			Paths:   []string{"volumeMounts[0].mountPath"},
+			Details: `mountPath: "mount/path"`,
+		},
+	}, {
+		name: "has valid volumeMounts",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/etc/secrets",
+				Name:      "secrets",
+			}},
+		},
+		want: nil,
+	}, {
+		name: "volumeMount without name",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/etc/secrets",
+			}},
+		},
+		want: apis.ErrMissingField("volumeMounts[0].name"),
+	}, {
+		name: "volumeMount within a reserved path",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/var/log/app",
+				Name:      "logs",
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "Volume mount path must not overlap a reserved path",
+			Paths:   []string{"volumeMounts[0].mountPath"},
+			Details: `mountPath: "/var/log/app" overlaps "/var/log"`,
+		},
+	}, {
+		name: "volumeMount containing a reserved path",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/",
+				Name:      "root",
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "Volume mount path must not overlap a reserved path",
+			Paths:   []string{"volumeMounts[0].mountPath"},
+			Details: `mountPath: "/" overlaps "/var/log"`,
+		},
+	}, {
+		name: "volumeMount sharing a prefix with a reserved path",
+		c: corev1.Container{
+			Image: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "/var/logs",
+				Name:      "logs",
+			}},
+		},
+		want: nil,
 	}, {
 		name: "has lifecycle",
 		c: corev1.Container{
@@ -248,6 +516,31 @@ func TestContainerValidation(t *testing.T) {
 			Lifecycle: &corev1.Lifecycle{},
 		},
 		want: apis.ErrDisallowedFields("lifecycle"),
+	}, {
+		name: "has security context",
+		c: corev1.Container{
+			Image: "foo",
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot:           &yes,
+				ReadOnlyRootFilesystem: &yes,
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "privileged",
+		c: corev1.Container{
+			Image: "foo",
+			SecurityContext: &corev1.SecurityContext{
+				Privileged: &yes,
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Privileged containers are not allowed",
+			Paths:   []string{"securityContext.privileged"},
+		},
 	}, {
 		name: "valid with probes (no port)",
 		c: corev1.Container{
@@ -294,113 +587,989 @@ func TestContainerValidation(t *testing.T) {
 		},
 		want: apis.ErrDisallowedFields("livenessProbe.tcpSocket.port"),
 	}, {
-		name: "has numerous problems",
+		name: "valid liveness probe successThreshold",
 		c: corev1.Container{
-			Name: "foo",
-			VolumeMounts: []corev1.VolumeMount{{
-				MountPath: "mount/path",
-				Name:      "name",
-			}},
-			Lifecycle: &corev1.Lifecycle{},
-		},
-		want: apis.ErrDisallowedFields("name", "volumeMounts", "lifecycle").Also(
-			&apis.FieldError{
-				Message: "Failed to parse image reference",
-				Paths:   []string{"image"},
-				Details: "image: \"\", error: could not parse reference",
+			Image: "foo",
+			LivenessProbe: &corev1.Probe{
+				SuccessThreshold: 1,
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{},
+				},
 			},
-		),
-	}}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			got := validateContainer(test.c)
-			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
-				t.Errorf("validateContainer (-want, +got) = %v", diff)
-			}
-		})
-	}
-}
-
-func TestBuildRefValidation(t *testing.T) {
-	tests := []struct {
-		name string
-		r    *corev1.ObjectReference
-		want *apis.FieldError
-	}{{
-		name: "nil",
-	}, {
-		name: "no api version",
-		r:    &corev1.ObjectReference{},
-		want: apis.ErrInvalidValue("", "apiVersion"),
-	}, {
-		name: "bad api version",
-		r: &corev1.ObjectReference{
-			APIVersion: "/v1alpha1",
 		},
-		want: apis.ErrInvalidValue("/v1alpha1", "apiVersion"),
+		want: nil,
 	}, {
-		name: "no kind",
-		r: &corev1.ObjectReference{
-			APIVersion: "foo/v1alpha1",
+		name: "invalid liveness probe successThreshold",
+		c: corev1.Container{
+			Image: "foo",
+			LivenessProbe: &corev1.Probe{
+				SuccessThreshold: 3,
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{},
+				},
+			},
 		},
-		want: apis.ErrInvalidValue("", "kind"),
-	}, {
-		name: "bad kind",
-		r: &corev1.ObjectReference{
-			APIVersion: "foo/v1alpha1",
-			Kind:       "Bad Kind",
+		want: &apis.FieldError{
+			Message: "successThreshold must be 1 for liveness probes",
+			Paths:   []string{"livenessProbe.successThreshold"},
+			Details: "successThreshold: 3",
 		},
-		want: apis.ErrInvalidValue("Bad Kind", "kind"),
 	}, {
-		name: "no namespace",
-		r: &corev1.ObjectReference{
-			APIVersion: "foo.group/v1alpha1",
-			Kind:       "Bar",
-			Name:       "the-bar-0001",
+		name: "readiness probe successThreshold is not restricted",
+		c: corev1.Container{
+			Image: "foo",
+			ReadinessProbe: &corev1.Probe{
+				SuccessThreshold: 3,
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{},
+				},
+			},
 		},
 		want: nil,
 	}, {
-		name: "no name",
-		r: &corev1.ObjectReference{
-			APIVersion: "foo.group/v1alpha1",
-			Kind:       "Bar",
-		},
-		want: apis.ErrInvalidValue("", "name"),
-	}, {
+		name: "valid resource limits",
+		c: corev1.Container{
+			Image: "foo",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+					corev1.ResourceCPU:    resource.MustParse("2"),
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "memory limit below request",
+		c: corev1.Container{
+			Image: "foo",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "memory limit must not be below its request",
+			Paths:   []string{"resources.limits.memory"},
+			Details: "request: 1Gi, limit: 256Mi",
+		},
+	}, {
+		name: "ephemeral storage limit above request",
+		c: corev1.Container{
+			Image: "foo",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse("50Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "ephemeral storage limit below request",
+		c: corev1.Container{
+			Image: "foo",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse("50Mi"),
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "ephemeral-storage limit must not be below its request",
+			Paths:   []string{"resources.limits.ephemeral-storage"},
+			Details: "request: 1Gi, limit: 50Mi",
+		},
+	}, {
+		name: "readiness probe with unset periodSeconds",
+		c: corev1.Container{
+			Image: "foo",
+			ReadinessProbe: &corev1.Probe{
+				PeriodSeconds: 0,
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "readiness probe with valid periodSeconds",
+		c: corev1.Container{
+			Image: "foo",
+			ReadinessProbe: &corev1.Probe{
+				PeriodSeconds: 1,
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "readiness probe with negative periodSeconds",
+		c: corev1.Container{
+			Image: "foo",
+			ReadinessProbe: &corev1.Probe{
+				PeriodSeconds: -1,
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{},
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "periodSeconds must be at least 1",
+			Paths:   []string{"readinessProbe.periodSeconds"},
+			Details: "periodSeconds: -1",
+		},
+	}, {
+		name: "liveness probe with negative timeoutSeconds",
+		c: corev1.Container{
+			Image: "foo",
+			LivenessProbe: &corev1.Probe{
+				TimeoutSeconds: -5,
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{},
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "timeoutSeconds must not be negative",
+			Paths:   []string{"livenessProbe.timeoutSeconds"},
+			Details: "timeoutSeconds: -5",
+		},
+	}, {
+		name: "valid termination message path",
+		c: corev1.Container{
+			Image:                  "foo",
+			TerminationMessagePath: "/dev/termination-log",
+		},
+		want: nil,
+	}, {
+		name: "relative termination message path",
+		c: corev1.Container{
+			Image:                  "foo",
+			TerminationMessagePath: "dev/termination-log",
+		},
+		want: &apis.FieldError{
+			Message: "Termination message path must be absolute",
+			Paths:   []string{"terminationMessagePath"},
+			Details: `terminationMessagePath: "dev/termination-log"`,
+		},
+	}, {
+		name: "termination message path within a mounted volume",
+		c: corev1.Container{
+			Image:                  "foo",
+			TerminationMessagePath: "/var/log/../log/termination-log",
+		},
+		want: &apis.FieldError{
+			Message: "Termination message path must not be within a mounted volume",
+			Paths:   []string{"terminationMessagePath"},
+			Details: `terminationMessagePath: "/var/log/../log/termination-log" is within "/var/log"`,
+		},
+	}, {
+		name: "termination message path sharing a prefix with a mounted volume",
+		c: corev1.Container{
+			Image:                  "foo",
+			TerminationMessagePath: "/var/logs/termination-log",
+		},
+		want: nil,
+	}, {
+		name: "has numerous problems",
+		c: corev1.Container{
+			Name: "foo",
+			VolumeMounts: []corev1.VolumeMount{{
+				MountPath: "mount/path",
+				Name:      "name",
+			}},
+			Lifecycle: &corev1.Lifecycle{},
+		},
+		want: apis.ErrDisallowedFields("name", "lifecycle").Also(
+			&apis.FieldError{
+				Message: "Failed to parse image reference",
+				Paths:   []string{"image"},
+				Details: "image: \"\", error: could not parse reference",
+			},
+		).Also(&apis.FieldError{
+			Message: "Volume mount path must be absolute",
+			Paths:   []string{"volumeMounts[0].mountPath"},
+			Details: `mountPath: "mount/path"`,
+		}),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateContainer(context.Background(), test.c)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateContainer (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestRegistryValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		image   string
+		want    *apis.FieldError
+	}{{
+		name:  "no allowlist",
+		image: "registry:5000/foo/bar",
+		want:  nil,
+	}, {
+		name:    "allowed registry",
+		allowed: []string{"mirror.example.com"},
+		image:   "mirror.example.com/foo/bar:latest",
+		want:    nil,
+	}, {
+		name:    "allowed registry with port",
+		allowed: []string{"mirror.example.com", "registry:5000"},
+		image:   "registry:5000/foo/bar",
+		want:    nil,
+	}, {
+		name:    "allowed registry on another port",
+		allowed: []string{"registry:5000"},
+		image:   "registry:5001/foo/bar",
+		want: &apis.FieldError{
+			Message: "Image registry is not allowed",
+			Paths:   []string{"image"},
+			Details: `registry "registry:5001" is not one of: registry:5000`,
+		},
+	}, {
+		name:    "allowed registry without port",
+		allowed: []string{"mirror.example.com"},
+		image:   "mirror.example.com:5000/foo/bar",
+		want: &apis.FieldError{
+			Message: "Image registry is not allowed",
+			Paths:   []string{"image"},
+			Details: `registry "mirror.example.com:5000" is not one of: mirror.example.com`,
+		},
+	}, {
+		name:    "default registry not allowed",
+		allowed: []string{"mirror.example.com", "registry:5000"},
+		image:   "busybox",
+		want: &apis.FieldError{
+			Message: "Image registry is not allowed",
+			Paths:   []string{"image"},
+			Details: `registry "index.docker.io" is not one of: mirror.example.com, registry:5000`,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := withWebhookConfig(func(wc *config.Webhook) {
+				wc.AllowedRegistries = test.allowed
+			})
+			got := validateContainer(ctx, corev1.Container{Image: test.image})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateContainer (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDigestValidation(t *testing.T) {
+	const digest = "sha256:deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+	tests := []struct {
+		name    string
+		require bool
+		image   string
+		want    *apis.FieldError
+	}{{
+		name:  "tag allowed when not required",
+		image: "foo:bar",
+		want:  nil,
+	}, {
+		name:    "digest when required",
+		require: true,
+		image:   "gcr.io/foo/bar@" + digest,
+		want:    nil,
+	}, {
+		name:    "tag when required",
+		require: true,
+		image:   "gcr.io/foo/bar:baz",
+		want: &apis.FieldError{
+			Message: "Image must be specified by digest",
+			Paths:   []string{"image"},
+			Details: `tag "baz" is mutable, use gcr.io/foo/bar@sha256:... instead`,
+		},
+	}, {
+		name:    "implicit latest tag when required",
+		require: true,
+		image:   "helloworld",
+		want: &apis.FieldError{
+			Message: "Image must be specified by digest",
+			Paths:   []string{"image"},
+			Details: `tag "latest" is mutable, use index.docker.io/library/helloworld@sha256:... instead`,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := withWebhookConfig(func(wc *config.Webhook) {
+				wc.RequireImageDigest = test.require
+			})
+			got := validateContainer(ctx, corev1.Container{Image: test.image})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateContainer (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDigestAlgorithmValidation(t *testing.T) {
+	const hex = "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+	tests := []struct {
+		name    string
+		allowed []string
+		image   string
+		want    *apis.FieldError
+	}{{
+		name:  "any algorithm when not restricted",
+		image: "gcr.io/foo/bar@sha512:" + hex,
+		want:  nil,
+	}, {
+		name:    "sha256 when restricted",
+		allowed: []string{"sha256"},
+		image:   "gcr.io/foo/bar@sha256:" + hex,
+		want:    nil,
+	}, {
+		name:    "tag when restricted",
+		allowed: []string{"sha256"},
+		image:   "gcr.io/foo/bar:baz",
+		want:    nil,
+	}, {
+		name:    "sha512 when restricted",
+		allowed: []string{"sha256"},
+		image:   "gcr.io/foo/bar@sha512:" + hex,
+		want: &apis.FieldError{
+			Message: "Image digest algorithm is not allowed",
+			Paths:   []string{"image"},
+			Details: `algorithm "sha512" is not one of sha256`,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := withWebhookConfig(func(wc *config.Webhook) {
+				wc.AllowedDigestAlgorithms = test.allowed
+			})
+			got := validateContainer(ctx, corev1.Container{Image: test.image})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateContainer (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestConfiguredQueuePortsValidation(t *testing.T) {
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.QueueProxyPorts = &config.QueueProxyPorts{Serving: 18012, Admin: 18022, Metrics: 19090}
+	ctx := config.ToContext(context.Background(), cfg)
+
+	tests := []struct {
+		name string
+		port int32
+		want *apis.FieldError
+	}{{
+		name: "default queue port is free",
+		port: 8012,
+		want: nil,
+	}, {
+		name: "configured admin port",
+		port: 18022,
+		want: &apis.FieldError{
+			Message: `invalid value "18022"`,
+			Paths:   []string{"ports.ContainerPort"},
+			Details: "ports 18012, 18022 and 19090 are reserved for the queue-proxy",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateContainer(ctx, corev1.Container{
+				Image: "foo",
+				Ports: []corev1.ContainerPort{{ContainerPort: test.port}},
+			})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateContainer (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestPlaceholderImageValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		placeholders []string
+		image        string
+		want         *apis.FieldError
+	}{{
+		name:  "real image",
+		image: "gcr.io/foo/bar",
+		want:  nil,
+	}, {
+		name:         "configured placeholder",
+		placeholders: []string{"ko://github.com/foo/bar", "TODO"},
+		image:        "TODO",
+		want: &apis.FieldError{
+			Message: "Image is a placeholder, set a real image or a buildRef",
+			Paths:   []string{"container.image"},
+			Details: `image: "TODO"`,
+		},
+	}, {
+		name:  "no placeholders configured",
+		image: "gcr.io/foo/bar:BUILD_PLACEHOLDER",
+		want:  nil,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := withWebhookConfig(func(wc *config.Webhook) {
+				wc.PlaceholderImages = test.placeholders
+			})
+			rs := &RevisionSpec{
+				Container: corev1.Container{Image: test.image},
+			}
+			got := validatePlaceholderImage(ctx, rs)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validatePlaceholderImage (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestReadinessProbeRequiredValidation(t *testing.T) {
+	probe := &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{},
+		},
+	}
+	tests := []struct {
+		name    string
+		require bool
+		rs      *RevisionSpec
+		want    *apis.FieldError
+	}{{
+		name: "no probe when not required",
+		rs: &RevisionSpec{
+			Container: corev1.Container{Image: "busybox"},
+		},
+		want: nil,
+	}, {
+		name:    "probe when required",
+		require: true,
+		rs: &RevisionSpec{
+			Container: corev1.Container{Image: "busybox", ReadinessProbe: probe},
+		},
+		want: nil,
+	}, {
+		name:    "no probe when required",
+		require: true,
+		rs: &RevisionSpec{
+			Container: corev1.Container{Image: "busybox"},
+		},
+		want: apis.ErrMissingField("container.readinessProbe"),
+	}, {
+		name:    "no serving container probe when required",
+		require: true,
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image:          "log-shipper",
+				ReadinessProbe: probe,
+			}, {
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+			}},
+		},
+		want: apis.ErrMissingField("containers[1].readinessProbe"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := withWebhookConfig(func(wc *config.Webhook) {
+				wc.RequireReadinessProbe = test.require
+			})
+			got := validateReadinessProbeRequired(ctx, test.rs)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateReadinessProbeRequired (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestExtendedResourceValidation(t *testing.T) {
+	ctx := withWebhookConfig(func(wc *config.Webhook) {
+		wc.AllowedExtendedResources = []string{"nvidia.com/gpu", "hugepages-2Mi"}
+	})
+
+	tests := []struct {
+		name      string
+		resources corev1.ResourceRequirements
+		want      *apis.FieldError
+	}{{
+		name: "standard resources",
+		resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("1"),
+				corev1.ResourceMemory:           resource.MustParse("1Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+			},
+		},
+		want: nil,
+	}, {
+		name: "allowed extended resource",
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				"nvidia.com/gpu": resource.MustParse("1"),
+			},
+		},
+		want: nil,
+	}, {
+		name: "disallowed extended resource",
+		resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				"example.com/fpga": resource.MustParse("1"),
+			},
+		},
+		want: &apis.FieldError{
+			Message: `Resource "example.com/fpga" is not allowed`,
+			Paths:   []string{"resources.requests.example.com/fpga"},
+			Details: "only cpu, memory, ephemeral-storage and the extended resources allowed by the operator may be used",
+		},
+	}, {
+		name: "allowed hugepages",
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				"hugepages-2Mi": resource.MustParse("100Mi"),
+			},
+		},
+		want: nil,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateContainer(ctx, corev1.Container{Image: "foo", Resources: test.resources})
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateContainer (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestHugePagesValidation(t *testing.T) {
+	ctx := withWebhookConfig(func(wc *config.Webhook) {
+		wc.AllowedExtendedResources = []string{"hugepages-2Mi"}
+	})
+
+	hugePages := func(name corev1.ResourceName) corev1.Container {
+		return corev1.Container{
+			Image: "foo",
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					name: resource.MustParse("1Gi"),
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		rs   *RevisionSpec
+		want *apis.FieldError
+	}{{
+		name: "allowed by the operator",
+		rs: &RevisionSpec{
+			Container: hugePages("hugepages-2Mi"),
+		},
+		want: nil,
+	}, {
+		name: "not configured by the operator",
+		rs: &RevisionSpec{
+			Container: hugePages("hugepages-1Gi"),
+		},
+		want: &apis.FieldError{
+			Message: `Resource "hugepages-1Gi" is not allowed`,
+			Paths:   []string{"container.resources.limits.hugepages-1Gi"},
+			Details: "hugepages are only available with a nodeSelector or toleration, or once the operator has configured them on the nodes and allowed them",
+		},
+	}, {
+		name: "not configured by the operator, in containers",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "log-shipper",
+			}, hugePages("hugepages-1Gi")},
+		},
+		want: &apis.FieldError{
+			Message: `Resource "hugepages-1Gi" is not allowed`,
+			Paths:   []string{"containers[1].resources.limits.hugepages-1Gi"},
+			Details: "hugepages are only available with a nodeSelector or toleration, or once the operator has configured them on the nodes and allowed them",
+		},
+	}, {
+		name: "with a node selector",
+		rs: &RevisionSpec{
+			Container:    hugePages("hugepages-1Gi"),
+			NodeSelector: map[string]string{"hugepages": "1Gi"},
+		},
+		want: nil,
+	}, {
+		name: "with a toleration",
+		rs: &RevisionSpec{
+			Container: hugePages("hugepages-1Gi"),
+			Tolerations: []corev1.Toleration{{
+				Key:      "hugepages",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}},
+		},
+		want: nil,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateHugePages(ctx, test.rs)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateHugePages (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestVolumeValidation(t *testing.T) {
+	secretVolume := func(name string) corev1.Volume {
+		return corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "foo"},
+			},
+		}
+	}
+	mounts := func(names ...string) []corev1.VolumeMount {
+		var ms []corev1.VolumeMount
+		for _, n := range names {
+			ms = append(ms, corev1.VolumeMount{Name: n, MountPath: "/etc/" + n})
+		}
+		return ms
+	}
+
+	tests := []struct {
+		name string
+		rs   *RevisionSpec
+		want *apis.FieldError
+	}{{
+		name: "secret and configMap volumes",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image:        "helloworld",
+				VolumeMounts: mounts("secret", "config"),
+			},
+			Volumes: []corev1.Volume{secretVolume("secret"), {
+				Name: "config",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "foo"},
+					},
+				},
+			}},
+		},
+		want: nil,
+	}, {
+		name: "hostPath volume",
+		rs: &RevisionSpec{
+			Container: corev1.Container{Image: "helloworld"},
+			Volumes: []corev1.Volume{{
+				Name: "host",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/"},
+				},
+			}},
+		},
+		want: apis.ErrMissingOneOf("volumes[0].secret", "volumes[0].configMap").Also(&apis.FieldError{
+			Message: "Only secret and configMap volumes are supported",
+			Paths:   []string{"volumes[0]"},
+		}),
+	}, {
+		name: "secret and configMap in one volume",
+		rs: &RevisionSpec{
+			Container: corev1.Container{Image: "helloworld"},
+			Volumes: []corev1.Volume{{
+				Name: "both",
+				VolumeSource: corev1.VolumeSource{
+					Secret:    &corev1.SecretVolumeSource{SecretName: "foo"},
+					ConfigMap: &corev1.ConfigMapVolumeSource{},
+				},
+			}},
+		},
+		want: apis.ErrMultipleOneOf("volumes[0].secret", "volumes[0].configMap"),
+	}, {
+		name: "invalid volume name",
+		rs: &RevisionSpec{
+			Container: corev1.Container{Image: "helloworld"},
+			Volumes:   []corev1.Volume{secretVolume("Not_Valid")},
+		},
+		want: apis.ErrInvalidValue("Not_Valid", "volumes[0].name"),
+	}, {
+		name: "reserved volume name",
+		rs: &RevisionSpec{
+			Container: corev1.Container{Image: "helloworld"},
+			Volumes:   []corev1.Volume{secretVolume("varlog")},
+		},
+		want: &apis.FieldError{
+			Message: `Volume name "varlog" is reserved`,
+			Paths:   []string{"volumes[0].name"},
+		},
+	}, {
+		name: "duplicate volume names",
+		rs: &RevisionSpec{
+			Container: corev1.Container{Image: "helloworld"},
+			Volumes:   []corev1.Volume{secretVolume("foo"), secretVolume("foo")},
+		},
+		want: &apis.FieldError{
+			Message: `Duplicate volume name "foo"`,
+			Paths:   []string{"volumes[1].name"},
+		},
+	}, {
+		name: "mount of an undeclared volume",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image:        "helloworld",
+				VolumeMounts: mounts("missing"),
+			},
+		},
+		want: &apis.FieldError{
+			Message: `Volume mount refers to undeclared volume "missing"`,
+			Paths:   []string{"container.volumeMounts[0].name"},
+		},
+	}, {
+		name: "sidecar mount of an undeclared volume",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+			}, {
+				Image:        "sidecar",
+				VolumeMounts: mounts("missing"),
+			}},
+		},
+		want: &apis.FieldError{
+			Message: `Volume mount refers to undeclared volume "missing"`,
+			Paths:   []string{"containers[1].volumeMounts[0].name"},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.rs.Validate(context.Background())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMaxVolumesValidation(t *testing.T) {
+	ctx := withWebhookConfig(func(wc *config.Webhook) {
+		wc.MaxVolumes = 2
+	})
+
+	spec := func(n int) *RevisionSpec {
+		rs := &RevisionSpec{Container: corev1.Container{Image: "helloworld"}}
+		for i := 0; i < n; i++ {
+			name := fmt.Sprintf("secret-%d", i)
+			rs.Volumes = append(rs.Volumes, corev1.Volume{
+				Name: name,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: name},
+				},
+			})
+			rs.Container.VolumeMounts = append(rs.Container.VolumeMounts, corev1.VolumeMount{
+				Name:      name,
+				MountPath: "/etc/" + name,
+			})
+		}
+		return rs
+	}
+
+	tests := []struct {
+		name string
+		rs   *RevisionSpec
+		want *apis.FieldError
+	}{{
+		name: "at the limit",
+		rs:   spec(2),
+		want: nil,
+	}, {
+		name: "above the limit",
+		rs:   spec(3),
+		want: (&apis.FieldError{
+			Message: "Too many volume mounts",
+			Paths:   []string{"container.volumeMounts"},
+			Details: "3 volume mounts, at most 2 are allowed",
+		}).Also(&apis.FieldError{
+			Message: "Too many volumes",
+			Paths:   []string{"volumes"},
+			Details: "3 volumes, at most 2 are allowed",
+		}),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.rs.Validate(ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestBuildRefValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		r    *corev1.ObjectReference
+		want *apis.FieldError
+	}{{
+		name: "nil",
+	}, {
+		name: "no api version",
+		r:    &corev1.ObjectReference{},
+		want: apis.ErrInvalidValue("", "apiVersion"),
+	}, {
+		name: "bad api version",
+		r: &corev1.ObjectReference{
+			APIVersion: "/v1alpha1",
+		},
+		want: apis.ErrInvalidValue("/v1alpha1", "apiVersion"),
+	}, {
+		name: "no kind",
+		r: &corev1.ObjectReference{
+			APIVersion: "foo/v1alpha1",
+		},
+		want: apis.ErrInvalidValue("", "kind"),
+	}, {
+		name: "bad kind",
+		r: &corev1.ObjectReference{
+			APIVersion: "foo/v1alpha1",
+			Kind:       "Bad Kind",
+		},
+		want: apis.ErrInvalidValue("Bad Kind", "kind"),
+	}, {
+		name: "no namespace",
+		r: &corev1.ObjectReference{
+			APIVersion: "foo.group/v1alpha1",
+			Kind:       "Bar",
+			Name:       "the-bar-0001",
+		},
+		want: nil,
+	}, {
+		name: "no name",
+		r: &corev1.ObjectReference{
+			APIVersion: "foo.group/v1alpha1",
+			Kind:       "Bar",
+		},
+		want: apis.ErrInvalidValue("", "name"),
+	}, {
 		name: "bad name",
 		r: &corev1.ObjectReference{
 			APIVersion: "foo.group/v1alpha1",
 			Kind:       "Bar",
 			Name:       "bad name",
 		},
-		want: apis.ErrInvalidValue("bad name", "name"),
+		want: apis.ErrInvalidValue("bad name", "name"),
+	}, {
+		name: "disallowed fields",
+		r: &corev1.ObjectReference{
+			APIVersion: "foo.group/v1alpha1",
+			Kind:       "Bar",
+			Name:       "bar0001",
+
+			Namespace:       "foo",
+			FieldPath:       "some.field.path",
+			ResourceVersion: "234234",
+			UID:             "deadbeefcafebabe",
+		},
+		want: apis.ErrDisallowedFields("namespace", "fieldPath", "resourceVersion", "uid"),
+	}, {
+		name: "all good",
+		r: &corev1.ObjectReference{
+			APIVersion: "foo.group/v1alpha1",
+			Kind:       "Bar",
+			Name:       "bar0001",
+		},
+		want: nil,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateBuildRef(context.Background(), test.r)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("validateBuildRef (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestAllowedBuildKindsValidation(t *testing.T) {
+	ctx := withWebhookConfig(func(wc *config.Webhook) {
+		wc.AllowedBuildKinds = []string{"Build.build.knative.dev", "PipelineRun.pipeline.example.com"}
+	})
+
+	tests := []struct {
+		name string
+		r    *corev1.ObjectReference
+		want *apis.FieldError
+	}{{
+		name: "allowed kind",
+		r: &corev1.ObjectReference{
+			APIVersion: "build.knative.dev/v1alpha1",
+			Kind:       "Build",
+			Name:       "build-0001",
+		},
+	}, {
+		name: "other allowed kind",
+		r: &corev1.ObjectReference{
+			APIVersion: "pipeline.example.com/v1beta1",
+			Kind:       "PipelineRun",
+			Name:       "run-0001",
+		},
 	}, {
-		name: "disallowed fields",
+		name: "kind from another group",
 		r: &corev1.ObjectReference{
 			APIVersion: "foo.group/v1alpha1",
-			Kind:       "Bar",
-			Name:       "bar0001",
-
-			Namespace:       "foo",
-			FieldPath:       "some.field.path",
-			ResourceVersion: "234234",
-			UID:             "deadbeefcafebabe",
+			Kind:       "Build",
+			Name:       "build-0001",
+		},
+		want: &apis.FieldError{
+			Message: "invalid value \"Build\"",
+			Paths:   []string{"kind"},
+			Details: "Build.foo.group is not one of: Build.build.knative.dev, PipelineRun.pipeline.example.com",
 		},
-		want: apis.ErrDisallowedFields("namespace", "fieldPath", "resourceVersion", "uid"),
 	}, {
-		name: "all good",
+		name: "unsupported kind",
 		r: &corev1.ObjectReference{
-			APIVersion: "foo.group/v1alpha1",
-			Kind:       "Bar",
-			Name:       "bar0001",
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       "config",
+		},
+		want: &apis.FieldError{
+			Message: "invalid value \"ConfigMap\"",
+			Paths:   []string{"kind"},
+			Details: "ConfigMap is not one of: Build.build.knative.dev, PipelineRun.pipeline.example.com",
 		},
-		want: nil,
 	}}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			got := validateBuildRef(test.r)
+			got := validateBuildRef(ctx, test.r)
 			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
 				t.Errorf("validateBuildRef (-want, +got) = %v", diff)
 			}
@@ -481,6 +1650,19 @@ func TestContainerConcurrencyValidation(t *testing.T) {
 		cc:   0,
 		cm:   RevisionRequestConcurrencyModelSingle,
 		want: apis.ErrMultipleOneOf("containerConcurrency", "concurrencyModel"),
+	}, {
+		name: "single with container concurrency (5)",
+		cc:   5,
+		cm:   RevisionRequestConcurrencyModelSingle,
+		want: &apis.FieldError{
+			Message: "containerConcurrency must be 1 when concurrencyModel is Single, got 5",
+			Paths:   []string{"containerConcurrency", "concurrencyModel"},
+		},
+	}, {
+		name: "multi with container concurrency (5)",
+		cc:   5,
+		cm:   RevisionRequestConcurrencyModelMulti,
+		want: nil,
 	}, {
 		name: "invalid container concurrency (too small)",
 		cc:   -1,
@@ -502,6 +1684,7 @@ func TestContainerConcurrencyValidation(t *testing.T) {
 }
 
 func TestRevisionSpecValidation(t *testing.T) {
+	yes, fsGroup, tolerationSeconds := true, int64(2000), int64(300)
 	tests := []struct {
 		name string
 		rs   *RevisionSpec
@@ -515,6 +1698,134 @@ func TestRevisionSpecValidation(t *testing.T) {
 			ConcurrencyModel: "Multi",
 		},
 		want: nil,
+	}, {
+		name: "has image pull secrets",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			ImagePullSecrets: []corev1.LocalObjectReference{{
+				Name: "registry.credentials",
+			}},
+		},
+		want: nil,
+	}, {
+		name: "has service account name",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			ServiceAccountName: "workload-identity",
+		},
+		want: nil,
+	}, {
+		name: "has bad service account name",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			ServiceAccountName: "Workload_Identity",
+		},
+		want: apis.ErrInvalidValue("Workload_Identity", "serviceAccountName"),
+	}, {
+		name: "has bad image pull secrets",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			ImagePullSecrets: []corev1.LocalObjectReference{{
+				Name: "Bad_Name",
+			}, {}},
+		},
+		want: apis.ErrInvalidValue("Bad_Name", "imagePullSecrets[0].name").Also(
+			apis.ErrMissingField("imagePullSecrets[1].name")),
+	}, {
+		name: "has build ref and tagged image",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "gcr.io/foo/helloworld:latest",
+			},
+			BuildRef: &corev1.ObjectReference{
+				APIVersion: "build.knative.dev/v1alpha1",
+				Kind:       "Build",
+				Name:       "foo",
+			},
+		},
+		want: nil,
+	}, {
+		name: "has build ref and digest pinned image",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "gcr.io/foo/helloworld@sha256:" + strings.Repeat("a", 64),
+			},
+			BuildRef: &corev1.ObjectReference{
+				APIVersion: "build.knative.dev/v1alpha1",
+				Kind:       "Build",
+				Name:       "foo",
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Image must not be pinned to a digest when it is produced by a build",
+			Paths:   []string{"container.image"},
+			Details: `image: "gcr.io/foo/helloworld@sha256:` + strings.Repeat("a", 64) + `", the build produces its own digest`,
+		},
+	}, {
+		name: "has build name and digest pinned serving container image",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "gcr.io/foo/sidecar@sha256:" + strings.Repeat("b", 64),
+			}, {
+				Image: "gcr.io/foo/helloworld@sha256:" + strings.Repeat("a", 64),
+				Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+			}},
+			BuildName: "foo",
+		},
+		want: &apis.FieldError{
+			Message: "Image must not be pinned to a digest when it is produced by a build",
+			Paths:   []string{"containers[1].image"},
+			Details: `image: "gcr.io/foo/helloworld@sha256:` + strings.Repeat("a", 64) + `", the build produces its own digest`,
+		},
+	}, {
+		name: "placeholder image without build",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "BUILD_PLACEHOLDER",
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Image is a placeholder, set a real image or a buildRef",
+			Paths:   []string{"container.image"},
+			Details: `image: "BUILD_PLACEHOLDER"`,
+		},
+	}, {
+		name: "placeholder image with build ref",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "BUILD_PLACEHOLDER",
+			},
+			BuildRef: &corev1.ObjectReference{
+				APIVersion: "build.knative.dev/v1alpha1",
+				Kind:       "Build",
+				Name:       "foo",
+			},
+		},
+		want: nil,
+	}, {
+		name: "placeholder sidecar image with build name",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "gcr.io/foo/helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+			}, {
+				Image: "BUILD_PLACEHOLDER",
+			}},
+			BuildName: "foo",
+		},
+		want: &apis.FieldError{
+			Message: "Image is a placeholder, set a real image or a buildRef",
+			Paths:   []string{"containers[1].image"},
+			Details: `image: "BUILD_PLACEHOLDER"`,
+		},
 	}, {
 		name: "has bad build ref",
 		rs: &RevisionSpec{
@@ -533,6 +1844,160 @@ func TestRevisionSpecValidation(t *testing.T) {
 			ConcurrencyModel: "bogus",
 		},
 		want: apis.ErrInvalidValue("bogus", "concurrencyModel"),
+	}, {
+		name: "containerConcurrency inconsistent with concurrency model",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			ContainerConcurrency: 1,
+			ConcurrencyModel:     "Multi",
+		},
+		want: apis.ErrMultipleOneOf("containerConcurrency", "concurrencyModel"),
+	}, {
+		name: "valid containers",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+			}, {
+				Image: "log-shipper",
+			}},
+		},
+		want: nil,
+	}, {
+		name: "sidecar probes with a port",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+			}, {
+				Image: "log-shipper",
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromInt(9000)},
+					},
+				},
+				LivenessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(9000)},
+					},
+				},
+			}},
+		},
+		want: nil,
+	}, {
+		name: "sidecar probes without a port",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+			}, {
+				Image: "log-shipper",
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"},
+					},
+				},
+				LivenessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						TCPSocket: &corev1.TCPSocketAction{},
+					},
+				},
+			}},
+		},
+		want: apis.ErrMissingField(
+			"containers[1].readinessProbe.httpGet.port",
+			"containers[1].livenessProbe.tcpSocket.port"),
+	}, {
+		name: "sidecar probe with a named port",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+			}, {
+				Image: "log-shipper",
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromString("http")},
+					},
+				},
+			}},
+		},
+		want: &apis.FieldError{
+			Message: `invalid value "http"`,
+			Paths:   []string{"containers[1].readinessProbe.httpGet.port"},
+			Details: "sidecar probes must use a port number",
+		},
+	}, {
+		name: "serving container probe with a port among containers",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromInt(8888)},
+					},
+				},
+			}, {
+				Image: "log-shipper",
+			}},
+		},
+		want: apis.ErrDisallowedFields("containers[0].readinessProbe.httpGet.port"),
+	}, {
+		name: "both container and containers",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			Containers: []corev1.Container{{
+				Image: "log-shipper",
+			}},
+		},
+		want: apis.ErrMultipleOneOf("container", "containers"),
+	}, {
+		name: "no container declares ports",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+			}, {
+				Image: "log-shipper",
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "Exactly one container must declare ports",
+			Paths:   []string{"containers"},
+			Details: "0 containers declare ports",
+		},
+	}, {
+		name: "several containers declare ports",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+			}, {
+				Image: "proxy",
+				Ports: []corev1.ContainerPort{{ContainerPort: 9999}},
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "Exactly one container must declare ports",
+			Paths:   []string{"containers"},
+			Details: "2 containers declare ports",
+		},
+	}, {
+		name: "invalid sidecar",
+		rs: &RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "helloworld",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8888}},
+			}, {
+				Name:  "shipper",
+				Image: "log-shipper",
+			}},
+		},
+		want: apis.ErrDisallowedFields("containers[1].name"),
 	}, {
 		name: "bad container spec",
 		rs: &RevisionSpec{
@@ -564,11 +2029,155 @@ func TestRevisionSpecValidation(t *testing.T) {
 		want: apis.ErrOutOfBoundsValue("-30s", "0s",
 			fmt.Sprintf("%ds", int(netv1alpha1.DefaultTimeout.Seconds())),
 			"timeoutSeconds"),
+	}, {
+		name: "has pod security context",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot: &yes,
+				FSGroup:      &fsGroup,
+			},
+		},
+		want: nil,
+	}, {
+		name: "has pod security context with sysctls",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			SecurityContext: &corev1.PodSecurityContext{
+				Sysctls: []corev1.Sysctl{{
+					Name:  "net.core.somaxconn",
+					Value: "1024",
+				}},
+			},
+		},
+		want: apis.ErrDisallowedFields("securityContext.sysctls"),
+	}, {
+		name: "has node selector and tolerations",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			NodeSelector: map[string]string{
+				"cloud.google.com/gke-accelerator": "nvidia-tesla-k80",
+			},
+			Tolerations: []corev1.Toleration{{
+				Key:      "nvidia.com/gpu",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}, {
+				Key:               "spot",
+				Value:             "true",
+				Effect:            corev1.TaintEffectNoExecute,
+				TolerationSeconds: &tolerationSeconds,
+			}, {
+				Operator: corev1.TolerationOpExists,
+			}},
+		},
+		want: nil,
+	}, {
+		name: "has bad node selector",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			NodeSelector: map[string]string{
+				"bad key": "foo",
+				"disk":    "bad value",
+			},
+		},
+		want: apis.ErrInvalidKeyName("bad key", "nodeSelector",
+			"name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')").
+			Also(apis.ErrInvalidValue("bad value", "nodeSelector[disk]")),
+	}, {
+		name: "has bad tolerations",
+		rs: &RevisionSpec{
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+			Tolerations: []corev1.Toleration{{
+				Operator: corev1.TolerationOpEqual,
+				Value:    "true",
+			}, {
+				Key:      "spot",
+				Operator: corev1.TolerationOpExists,
+				Value:    "true",
+			}, {
+				Key:      "spot",
+				Operator: "Matches",
+			}, {
+				Key:    "spot",
+				Effect: "NoRun",
+			}, {
+				Key:               "spot",
+				Effect:            corev1.TaintEffectNoSchedule,
+				TolerationSeconds: &tolerationSeconds,
+			}},
+		},
+		want: apis.ErrInvalidValue("Equal", "tolerations[0].operator").
+			Also(apis.ErrDisallowedFields("tolerations[1].value")).
+			Also(apis.ErrInvalidValue("Matches", "tolerations[2].operator")).
+			Also(apis.ErrInvalidValue("NoRun", "tolerations[3].effect")).
+			Also(apis.ErrDisallowedFields("tolerations[4].tolerationSeconds")),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.rs.Validate(context.Background())
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestSingleConcurrencyTimeoutValidation(t *testing.T) {
+	ctx := withWebhookConfig(func(wc *config.Webhook) {
+		wc.SingleConcurrencyMaxTimeoutSeconds = 30
+	})
+
+	tests := []struct {
+		name string
+		rs   *RevisionSpec
+		want *apis.FieldError
+	}{{
+		name: "single concurrency within the single max",
+		rs: &RevisionSpec{
+			ContainerConcurrency: 1,
+			TimeoutSeconds:       30,
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+		},
+		want: nil,
+	}, {
+		name: "single concurrency exceeding the single max",
+		rs: &RevisionSpec{
+			ContainerConcurrency: 1,
+			TimeoutSeconds:       60,
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+		},
+		want: apis.ErrOutOfBoundsValue("60s", "0s", "30s", "timeoutSeconds"),
+	}, {
+		name: "multi concurrency allowed the global max",
+		rs: &RevisionSpec{
+			ContainerConcurrency: 0,
+			TimeoutSeconds:       int64(netv1alpha1.DefaultTimeout.Seconds()),
+			Container: corev1.Container{
+				Image: "helloworld",
+			},
+		},
+		want: nil,
 	}}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			got := test.rs.Validate()
+			got := test.rs.Validate(ctx)
 			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
 				t.Errorf("Validate (-want, +got) = %v", diff)
 			}
@@ -608,11 +2217,33 @@ func TestRevisionTemplateSpecValidation(t *testing.T) {
 			},
 		},
 		want: apis.ErrDisallowedFields("spec.container.name"),
+	}, {
+		name: "command with build provided entrypoint",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.BuildEntrypointAnnotationKey: "true",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image:   "helloworld",
+					Command: []string{"/ko-app/helloworld"},
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Command must not be set when the build provides the entrypoint",
+			Paths: []string{
+				"metadata.annotations." + serving.BuildEntrypointAnnotationKey,
+				"spec.container.command",
+			},
+		},
 	}}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			got := test.rts.Validate()
+			got := test.rts.Validate(context.Background())
 			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
 				t.Errorf("Validate (-want, +got) = %v", diff)
 			}
@@ -652,6 +2283,18 @@ func TestRevisionValidation(t *testing.T) {
 			},
 		},
 		want: apis.ErrDisallowedFields("spec.container.name"),
+	}, {
+		name: "nested concurrency error",
+		r: &Revision{
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+				ContainerConcurrency: 1,
+				ConcurrencyModel:     "Multi",
+			},
+		},
+		want: apis.ErrMultipleOneOf("spec.containerConcurrency", "spec.concurrencyModel"),
 	}, {
 		name: "invalid name - dots",
 		r: &Revision{
@@ -701,6 +2344,74 @@ func TestRevisionValidation(t *testing.T) {
 			},
 		},
 		want: &apis.FieldError{Message: "Invalid resource name: length must be no more than 63 characters", Paths: []string{"metadata.name"}},
+	}, {
+		name: "command with build provided entrypoint",
+		r: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.BuildEntrypointAnnotationKey: "true",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image:   "helloworld",
+					Command: []string{"/ko-app/helloworld"},
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Command must not be set when the build provides the entrypoint",
+			Paths: []string{
+				"metadata.annotations." + serving.BuildEntrypointAnnotationKey,
+				"spec.container.command",
+			},
+		},
+	}, {
+		name: "no command with build provided entrypoint",
+		r: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.BuildEntrypointAnnotationKey: "true",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "command without build provided entrypoint",
+		r: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.BuildEntrypointAnnotationKey: "false",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image:   "helloworld",
+					Command: []string{"/ko-app/helloworld"},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid build provided entrypoint annotation",
+		r: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.BuildEntrypointAnnotationKey: "yes please",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("yes please", "metadata.annotations."+serving.BuildEntrypointAnnotationKey),
 	}}
 
 	for _, test := range tests {
@@ -713,12 +2424,140 @@ func TestRevisionValidation(t *testing.T) {
 	}
 }
 
+func TestValidateRevisionBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want *apis.FieldError
+	}{{
+		name: "valid yaml",
+		in: `apiVersion: serving.knative.dev/v1alpha1
+kind: Revision
+metadata:
+  name: hello
+spec:
+  container:
+    image: helloworld
+`,
+		want: nil,
+	}, {
+		name: "valid json",
+		in:   `{"kind": "Revision", "metadata": {"name": "hello"}, "spec": {"container": {"image": "helloworld"}}}`,
+		want: nil,
+	}, {
+		name: "invalid spec",
+		in: `kind: Revision
+metadata:
+  name: hello
+spec:
+  container:
+    name: kevin
+    image: helloworld
+`,
+		want: apis.ErrDisallowedFields("spec.container.name"),
+	}, {
+		name: "wrong kind",
+		in: `kind: Configuration
+metadata:
+  name: hello
+`,
+		want: apis.ErrInvalidValue("Configuration", "kind"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ValidateRevisionBytes(context.Background(), []byte(test.in))
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("ValidateRevisionBytes (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestValidateRevisionBytesWithConfig(t *testing.T) {
+	ctx := withWebhookConfig(func(wc *config.Webhook) {
+		wc.RequireImageDigest = true
+	})
+	in := `kind: Revision
+metadata:
+  name: hello
+spec:
+  container:
+    image: gcr.io/foo/bar:baz
+`
+	want := &apis.FieldError{
+		Message: "Image must be specified by digest",
+		Paths:   []string{"spec.container.image"},
+		Details: `tag "baz" is mutable, use gcr.io/foo/bar@sha256:... instead`,
+	}
+	got := ValidateRevisionBytes(ctx, []byte(in))
+	if diff := cmp.Diff(want.Error(), got.Error()); diff != "" {
+		t.Errorf("ValidateRevisionBytes (-want, +got) = %v", diff)
+	}
+}
+
+func TestValidateRevisionBytesMalformed(t *testing.T) {
+	got := ValidateRevisionBytes(context.Background(), []byte("spec: [not a spec"))
+	if got == nil || got.Message != "Failed to parse Revision" {
+		t.Errorf("ValidateRevisionBytes() = %v, wanted a parse error", got)
+	}
+}
+
 type notARevision struct{}
 
 func (nar *notARevision) CheckImmutableFields(apis.Immutable) *apis.FieldError {
 	return nil
 }
 
+func TestRequiredLabelsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		required []string
+		labels   map[string]string
+		want     *apis.FieldError
+	}{{
+		name:   "no required labels",
+		labels: nil,
+		want:   nil,
+	}, {
+		name:     "required labels present",
+		required: []string{"team", "env"},
+		labels:   map[string]string{"team": "serving", "env": "prod", "other": "x"},
+		want:     nil,
+	}, {
+		name:     "required labels missing",
+		required: []string{"team", "env", "tier"},
+		labels:   map[string]string{"env": "prod"},
+		want: &apis.FieldError{
+			Message: "Missing required labels: team, tier",
+			Paths:   []string{"metadata.labels"},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := withWebhookConfig(func(wc *config.Webhook) {
+				wc.RequiredLabels = test.required
+			})
+			r := &Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "valid",
+					Labels: test.labels,
+				},
+				Spec: RevisionSpec{
+					Container: corev1.Container{
+						Image: "helloworld",
+					},
+				},
+			}
+			got := r.ValidateContext(ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
 func TestImmutableFields(t *testing.T) {
 	tests := []struct {
 		name string
@@ -744,6 +2583,133 @@ func TestImmutableFields(t *testing.T) {
 			},
 		},
 		want: nil,
+	}, {
+		name: "good (mutable field change)",
+		new: &Revision{
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+				ConcurrencyModel:       "Multi",
+				DeprecatedServingState: DeprecatedRevisionServingStateRetired,
+			},
+		},
+		old: &Revision{
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+				ConcurrencyModel:       "Multi",
+				DeprecatedServingState: DeprecatedRevisionServingStateActive,
+			},
+		},
+		want: nil,
+	}, {
+		name: "bad (node selector change)",
+		new: &Revision{
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+				NodeSelector: map[string]string{"disk": "ssd"},
+			},
+		},
+		old: &Revision{
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Immutable fields changed (-old +new)",
+			Paths:   []string{"spec"},
+			Details: `{v1alpha1.RevisionSpec}.NodeSelector:
+	-: map[string]string(nil)
+	+: map[string]string{"disk": "ssd"}
+`,
+		},
+	}, {
+		name: "good (autoscaling annotation change)",
+		new: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					autoscaling.MaxScaleAnnotationKey: "10",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		old: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					autoscaling.MaxScaleAnnotationKey: "5",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "good (debug sidecar annotation cleared)",
+		new: &Revision{
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		old: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.DebugSidecarAnnotationKey: "true",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "bad (active deadline annotation change)",
+		new: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.JobStyleAnnotationKey:              "true",
+					serving.ActiveDeadlineSecondsAnnotationKey: "60",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		old: &Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.JobStyleAnnotationKey: "true",
+				},
+			},
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Immutable annotation changed, create a new Revision to change it",
+			Paths:   []string{"metadata.annotations." + serving.ActiveDeadlineSecondsAnnotationKey},
+			Details: `"" -> "60"`,
+		},
 	}, {
 		name: "bad (type mismatch)",
 		new: &Revision{
@@ -835,12 +2801,32 @@ func TestImmutableFields(t *testing.T) {
 			},
 		},
 		want: &apis.FieldError{
-			Message: "Immutable fields changed (-old +new)",
-			Paths:   []string{"spec"},
-			Details: `{v1alpha1.RevisionSpec}.ConcurrencyModel:
-	-: v1alpha1.RevisionRequestConcurrencyModelType("Single")
-	+: v1alpha1.RevisionRequestConcurrencyModelType("Multi")
-`,
+			Message: "Immutable field changed, create a new Revision to change concurrency",
+			Paths:   []string{"spec.concurrencyModel"},
+			Details: `"Single" -> "Multi"`,
+		},
+	}, {
+		name: "bad (container concurrency change)",
+		new: &Revision{
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+				ContainerConcurrency: 10,
+			},
+		},
+		old: &Revision{
+			Spec: RevisionSpec{
+				Container: corev1.Container{
+					Image: "helloworld",
+				},
+				ContainerConcurrency: 1,
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Immutable field changed, create a new Revision to change concurrency",
+			Paths:   []string{"spec.containerConcurrency"},
+			Details: "1 -> 10",
 		},
 	}, {
 		name: "bad (multiple changes)",
@@ -860,17 +2846,18 @@ func TestImmutableFields(t *testing.T) {
 				ConcurrencyModel: "Single",
 			},
 		},
-		want: &apis.FieldError{
+		want: (&apis.FieldError{
+			Message: "Immutable field changed, create a new Revision to change concurrency",
+			Paths:   []string{"spec.concurrencyModel"},
+			Details: `"Single" -> "Multi"`,
+		}).Also(&apis.FieldError{
 			Message: "Immutable fields changed (-old +new)",
 			Paths:   []string{"spec"},
-			Details: `{v1alpha1.RevisionSpec}.ConcurrencyModel:
-	-: v1alpha1.RevisionRequestConcurrencyModelType("Single")
-	+: v1alpha1.RevisionRequestConcurrencyModelType("Multi")
-{v1alpha1.RevisionSpec}.Container.Image:
+			Details: `{v1alpha1.RevisionSpec}.Container.Image:
 	-: "busybox"
 	+: "helloworld"
 `,
-		},
+		}),
 	}}
 
 	for _, test := range tests {
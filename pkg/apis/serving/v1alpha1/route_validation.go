@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
@@ -26,8 +27,16 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+// Validate makes sure that Route is properly configured, with the default
+// policies.
 func (r *Route) Validate() *apis.FieldError {
-	return ValidateObjectMetadata(r.GetObjectMeta()).ViaField("metadata").
+	return r.ValidateContext(context.Background())
+}
+
+// ValidateContext makes sure that Route is properly configured, with the
+// policies of the config.Config in the context.
+func (r *Route) ValidateContext(ctx context.Context) *apis.FieldError {
+	return ValidateObjectMetadata(ctx, r.GetObjectMeta()).ViaField("metadata").
 		Also(r.Spec.Validate().ViaField("spec"))
 }
 
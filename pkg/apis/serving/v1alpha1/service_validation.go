@@ -17,19 +17,26 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/knative/pkg/apis"
 )
 
-// Validate validates the fields belonging to Service
+// Validate validates the fields belonging to Service, with the default policies.
 func (s *Service) Validate() *apis.FieldError {
-	return ValidateObjectMetadata(s.GetObjectMeta()).ViaField("metadata").
-		Also(s.Spec.Validate().ViaField("spec"))
+	return s.ValidateContext(context.Background())
+}
+
+// ValidateContext validates the fields belonging to Service, with the
+// policies of the config.Config in the context.
+func (s *Service) ValidateContext(ctx context.Context) *apis.FieldError {
+	return ValidateObjectMetadata(ctx, s.GetObjectMeta()).ViaField("metadata").
+		Also(s.Spec.Validate(ctx).ViaField("spec"))
 }
 
 // Validate validates the fields belonging to ServiceSpec recursively
-func (ss *ServiceSpec) Validate() *apis.FieldError {
+func (ss *ServiceSpec) Validate(ctx context.Context) *apis.FieldError {
 	// We would do this semantic DeepEqual, but the spec is comprised
 	// entirely of a oneof, the validation for which produces a clearer
 	// error message.
@@ -42,11 +49,11 @@ func (ss *ServiceSpec) Validate() *apis.FieldError {
 
 	if ss.RunLatest != nil {
 		set = append(set, "runLatest")
-		errs = errs.Also(ss.RunLatest.Validate().ViaField("runLatest"))
+		errs = errs.Also(ss.RunLatest.Validate(ctx).ViaField("runLatest"))
 	}
 	if ss.Release != nil {
 		set = append(set, "release")
-		errs = errs.Also(ss.Release.Validate().ViaField("release"))
+		errs = errs.Also(ss.Release.Validate(ctx).ViaField("release"))
 	}
 	if ss.Manual != nil {
 		set = append(set, "manual")
@@ -54,7 +61,7 @@ func (ss *ServiceSpec) Validate() *apis.FieldError {
 	}
 	if ss.Pinned != nil {
 		set = append(set, "pinned")
-		errs = errs.Also(ss.Pinned.Validate().ViaField("pinned"))
+		errs = errs.Also(ss.Pinned.Validate(ctx).ViaField("pinned"))
 	}
 
 	if len(set) > 1 {
@@ -66,17 +73,17 @@ func (ss *ServiceSpec) Validate() *apis.FieldError {
 }
 
 // Validate validates the fields belonging to PinnedType
-func (pt *PinnedType) Validate() *apis.FieldError {
+func (pt *PinnedType) Validate(ctx context.Context) *apis.FieldError {
 	var errs *apis.FieldError
 	if pt.RevisionName == "" {
 		errs = apis.ErrMissingField("revisionName")
 	}
-	return errs.Also(pt.Configuration.Validate().ViaField("configuration"))
+	return errs.Also(pt.Configuration.Validate(ctx).ViaField("configuration"))
 }
 
 // Validate validates the fields belonging to RunLatestType
-func (rlt *RunLatestType) Validate() *apis.FieldError {
-	return rlt.Configuration.Validate().ViaField("configuration")
+func (rlt *RunLatestType) Validate(ctx context.Context) *apis.FieldError {
+	return rlt.Configuration.Validate(ctx).ViaField("configuration")
 }
 
 // Validate validates the fields belonging to ManualType
@@ -85,7 +92,7 @@ func (m *ManualType) Validate() *apis.FieldError {
 }
 
 // Validate validates the fields belonging to ReleaseType
-func (rt *ReleaseType) Validate() *apis.FieldError {
+func (rt *ReleaseType) Validate(ctx context.Context) *apis.FieldError {
 	var errs *apis.FieldError
 	minRevisions := 1
 	maxRevisions := 2
@@ -111,5 +118,5 @@ func (rt *ReleaseType) Validate() *apis.FieldError {
 		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%v", rt.RolloutPercent), "rolloutPercent"))
 	}
 
-	return errs.Also(rt.Configuration.Validate().ViaField("configuration"))
+	return errs.Also(rt.Configuration.Validate(ctx).ViaField("configuration"))
 }
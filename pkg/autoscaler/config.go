@@ -49,6 +49,10 @@ type Config struct {
 	TickInterval   time.Duration
 
 	ScaleToZeroGracePeriod time.Duration
+
+	// MaxWarmPoolSize caps the number of pre-started Pods a PodAutoscaler
+	// may request through the warmPool annotation. Zero disables warm pools.
+	MaxWarmPoolSize int32
 }
 
 // TargetConcurrency calculates the target concurrency for a given container-concurrency
@@ -147,6 +151,17 @@ func NewConfigFromMap(data map[string]string) (*Config, error) {
 		}
 	}
 
+	if raw, ok := data["max-warm-pool-size"]; ok {
+		val, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		if val < 0 {
+			return nil, fmt.Errorf("max-warm-pool-size must not be negative, got %d", val)
+		}
+		lc.MaxWarmPoolSize = int32(val)
+	}
+
 	if lc.ScaleToZeroGracePeriod < 30*time.Second {
 		return nil, fmt.Errorf("scale-to-zero-grace-period must be at least 30s, got %v", lc.ScaleToZeroGracePeriod)
 	}
@@ -197,6 +197,39 @@ func TestNewConfig(t *testing.T) {
 			"tick-interval":                           "2s",
 		},
 		wantErr: true,
+	}, {
+		name: "with max warm pool size",
+		input: map[string]string{
+			"max-scale-up-rate":                       "1.0",
+			"container-concurrency-target-percentage": "0.5",
+			"container-concurrency-target-default":    "10.0",
+			"stable-window":                           "5m",
+			"panic-window":                            "10s",
+			"tick-interval":                           "2s",
+			"max-warm-pool-size":                      "3",
+		},
+		want: &Config{
+			ContainerConcurrencyTargetPercentage: 0.5,
+			ContainerConcurrencyTargetDefault:    10.0,
+			MaxScaleUpRate:                       1.0,
+			StableWindow:                         5 * time.Minute,
+			PanicWindow:                          10 * time.Second,
+			ScaleToZeroGracePeriod:               30 * time.Second,
+			TickInterval:                         2 * time.Second,
+			MaxWarmPoolSize:                      3,
+		},
+	}, {
+		name: "negative max warm pool size",
+		input: map[string]string{
+			"max-scale-up-rate":                       "1.0",
+			"container-concurrency-target-percentage": "0.5",
+			"container-concurrency-target-default":    "10.0",
+			"stable-window":                           "5m",
+			"panic-window":                            "10s",
+			"tick-interval":                           "2s",
+			"max-warm-pool-size":                      "-1",
+		},
+		wantErr: true,
 	}, {
 		name: "malformed float",
 		input: map[string]string{
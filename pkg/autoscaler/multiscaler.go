@@ -49,6 +49,9 @@ type Metric struct {
 // MetricSpec is the parameters in which the Revision should scaled.
 type MetricSpec struct {
 	TargetConcurrency float64
+	// TickInterval overrides the configured tick interval of the Metric's
+	// scaler when non-zero.
+	TickInterval time.Duration
 }
 
 // MetricStatus is the current scale recommendation.
@@ -77,9 +80,29 @@ type scalerRunner struct {
 	scaler UniScaler
 	stopCh chan struct{}
 
-	// mux guards access to metric
-	mux    sync.RWMutex
-	metric Metric
+	// tickIntervalCh passes a new tick interval to the ticking goroutine.
+	tickIntervalCh chan time.Duration
+
+	// mux guards access to metric and tickInterval
+	mux          sync.RWMutex
+	metric       Metric
+	tickInterval time.Duration
+}
+
+// resetTicker makes the scaler tick at the given interval. It must be called
+// with mux held, so that a single sender writes to tickIntervalCh.
+func (sr *scalerRunner) resetTicker(interval time.Duration) {
+	if interval == sr.tickInterval {
+		return
+	}
+	sr.tickInterval = interval
+	// Replace an interval the goroutine has not picked up yet, rather than
+	// block while it is busy ticking.
+	select {
+	case <-sr.tickIntervalCh:
+	default:
+	}
+	sr.tickIntervalCh <- interval
 }
 
 func (sr *scalerRunner) getLatestScale() int32 {
@@ -176,6 +199,7 @@ func (m *MultiScaler) Update(ctx context.Context, metric *Metric) (*Metric, erro
 		defer scaler.mux.Unlock()
 		scaler.metric = *metric
 		scaler.scaler.Update(metric.Spec)
+		scaler.resetTicker(m.tickInterval(metric.Spec))
 		return metric, nil
 	}
 	// This GroupResource is a lie, but unfortunately this interface requires one.
@@ -225,14 +249,17 @@ func (m *MultiScaler) createScaler(ctx context.Context, metric *Metric) (*scaler
 	}
 
 	stopCh := make(chan struct{})
+	tickIntervalCh := make(chan time.Duration, 1)
 	runner := &scalerRunner{
-		scaler: scaler,
-		stopCh: stopCh,
-		metric: *metric,
+		scaler:         scaler,
+		stopCh:         stopCh,
+		tickIntervalCh: tickIntervalCh,
+		metric:         *metric,
+		tickInterval:   m.tickInterval(metric.Spec),
 	}
 	runner.metric.Status.DesiredScale = -1
 
-	ticker := time.NewTicker(m.dynConfig.Current().TickInterval)
+	ticker := time.NewTicker(runner.tickInterval)
 
 	scaleChan := make(chan int32, scaleBufferSize)
 
@@ -247,6 +274,9 @@ func (m *MultiScaler) createScaler(ctx context.Context, metric *Metric) (*scaler
 				return
 			case <-ticker.C:
 				m.tickScaler(ctx, scaler, scaleChan)
+			case tickInterval := <-tickIntervalCh:
+				ticker.Stop()
+				ticker = time.NewTicker(tickInterval)
 			}
 		}
 	}()
@@ -270,6 +300,15 @@ func (m *MultiScaler) createScaler(ctx context.Context, metric *Metric) (*scaler
 	return runner, nil
 }
 
+// tickInterval returns the interval the scaler of a Metric with the given
+// spec ticks at.
+func (m *MultiScaler) tickInterval(spec MetricSpec) time.Duration {
+	if spec.TickInterval > 0 {
+		return spec.TickInterval
+	}
+	return m.dynConfig.Current().TickInterval
+}
+
 func (m *MultiScaler) tickScaler(ctx context.Context, scaler UniScaler, scaleChan chan<- int32) {
 	logger := logging.FromContext(ctx)
 	desiredScale, scaled := scaler.Scale(ctx, time.Now())
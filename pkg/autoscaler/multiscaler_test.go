@@ -109,6 +109,94 @@ func TestMultiScalerScaling(t *testing.T) {
 	}
 }
 
+func TestMultiScalerTickIntervalOverride(t *testing.T) {
+	ctx := context.TODO()
+	// With the configured tick interval, we would not see a tick during the test.
+	ms, stopCh, uniScaler := createMultiScaler(t, &autoscaler.Config{
+		TickInterval: time.Hour,
+	})
+	defer close(stopCh)
+
+	metric := newMetric()
+	metric.Spec.TickInterval = time.Millisecond
+
+	uniScaler.setScaleResult(1, true)
+
+	done := make(chan struct{})
+	defer close(done)
+	ms.Watch(func(key string) {
+		done <- struct{}{}
+	})
+
+	_, err := ms.Create(ctx, metric)
+	if err != nil {
+		t.Errorf("Create() = %v", err)
+	}
+
+	// Verify that we see a "tick" at the Metric's own interval.
+	select {
+	case <-done:
+		// We got the signal!
+	case <-time.After(30 * time.Millisecond):
+		t.Fatalf("timed out waiting for Watch()")
+	}
+
+	err = ms.Delete(ctx, metric.Namespace, metric.Name)
+	if err != nil {
+		t.Errorf("Delete() = %v", err)
+	}
+}
+
+func TestMultiScalerTickIntervalUpdate(t *testing.T) {
+	ctx := context.TODO()
+	ms, stopCh, uniScaler := createMultiScaler(t, &autoscaler.Config{
+		TickInterval: time.Millisecond,
+	})
+	defer close(stopCh)
+
+	// With this tick interval, we would not see a tick during the test.
+	metric := newMetric()
+	metric.Spec.TickInterval = time.Hour
+
+	uniScaler.setScaleResult(1, true)
+
+	done := make(chan struct{})
+	defer close(done)
+	ms.Watch(func(key string) {
+		done <- struct{}{}
+	})
+
+	_, err := ms.Create(ctx, metric)
+	if err != nil {
+		t.Errorf("Create() = %v", err)
+	}
+
+	select {
+	case <-done:
+		t.Fatalf("Got unexpected tick")
+	case <-time.After(30 * time.Millisecond):
+		// We got nothing!
+	}
+
+	// Going back to the configured tick interval resets the ticker.
+	metric.Spec.TickInterval = 0
+	if _, err := ms.Update(ctx, metric); err != nil {
+		t.Errorf("Update() = %v", err)
+	}
+
+	select {
+	case <-done:
+		// We got the signal!
+	case <-time.After(30 * time.Millisecond):
+		t.Fatalf("timed out waiting for Watch()")
+	}
+
+	err = ms.Delete(ctx, metric.Namespace, metric.Name)
+	if err != nil {
+		t.Errorf("Delete() = %v", err)
+	}
+}
+
 func TestMultiScalerScaleToZero(t *testing.T) {
 	ctx := context.TODO()
 	ms, stopCh, uniScaler := createMultiScaler(t, &autoscaler.Config{
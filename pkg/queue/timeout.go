@@ -31,7 +31,7 @@ var defaultTimeoutBody = "<html><head><title>Timeout</title></head><body><h1>Tim
 //
 // The new Handler calls h.ServeHTTP to handle each request, but if a
 // call runs for longer than its time limit, the handler responds with
-// a 503 Service Unavailable error and the given message in its body.
+// a 504 Gateway Timeout error and the given message in its body.
 // (If msg is empty, a suitable default message will be sent.)
 // After such a timeout, writes by h to its ResponseWriter will return
 // ErrHandlerTimeout.
@@ -155,7 +155,7 @@ func (tw *timeoutWriter) TimeoutAndWriteError(msg string) bool {
 	defer tw.mu.Unlock()
 
 	if !tw.wroteOnce {
-		tw.w.WriteHeader(http.StatusServiceUnavailable)
+		tw.w.WriteHeader(http.StatusGatewayTimeout)
 		io.WriteString(tw.w, msg)
 
 		tw.timedOut = true
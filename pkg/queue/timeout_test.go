@@ -53,7 +53,7 @@ func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
 				writeErrors <- werr
 			})
 		},
-		wantStatus:     http.StatusServiceUnavailable,
+		wantStatus:     http.StatusGatewayTimeout,
 		wantBody:       defaultTimeoutBody,
 		wantWriteError: true,
 	}, {
@@ -79,7 +79,7 @@ func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
 			})
 		},
 		timeoutMessage: "request timeout",
-		wantStatus:     http.StatusServiceUnavailable,
+		wantStatus:     http.StatusGatewayTimeout,
 		wantBody:       "request timeout",
 		wantWriteError: true,
 	}, {
@@ -90,7 +90,7 @@ func TestTimeToFirstByteTimeoutHandler(t *testing.T) {
 				panic(http.ErrAbortHandler)
 			})
 		},
-		wantStatus: http.StatusServiceUnavailable,
+		wantStatus: http.StatusGatewayTimeout,
 		wantBody:   "request timeout",
 		wantPanic:  true,
 	}}
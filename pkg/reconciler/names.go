@@ -16,12 +16,32 @@ limitations under the License.
 
 package reconciler
 
-import "fmt"
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
 
 func GetK8sServiceFullname(name string, namespace string) string {
 	return fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace)
 }
 
 func GetServingK8SServiceNameForObj(name string) string {
-	return name + "-service"
+	return ChildName(name, "-service")
+}
+
+// ChildName returns the name of a child resource: the name of its parent
+// with the given suffix. Names that would not fit in a DNS label, as
+// Services require, keep a prefix of the parent's name followed by a hash
+// of it, so that they stay unique.
+func ChildName(parent, suffix string) string {
+	name := parent + suffix
+	if len(name) <= validation.DNS1035LabelMaxLength {
+		return name
+	}
+	sum := md5.Sum([]byte(parent))
+	hash := hex.EncodeToString(sum[:])
+	return parent[:validation.DNS1035LabelMaxLength-len(hash)-len(suffix)] + hash + suffix
 }
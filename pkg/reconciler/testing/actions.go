@@ -53,6 +53,7 @@ func (l ActionRecorderList) ActionsByVerb() (Actions, error) {
 			case "patch":
 				a.Patches = append(a.Patches,
 					action.(clientgotesting.PatchAction))
+			case "get": // avoid 'unexpected verb get' error
 			case "list": // avoid 'unexpected verb list' error
 			case "watch": // avoid 'unexpected verb watch' error
 			default:
@@ -19,7 +19,9 @@ package testing
 import (
 	"fmt"
 
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	clientgotesting "k8s.io/client-go/testing"
 
 	"github.com/knative/pkg/apis"
@@ -40,6 +42,21 @@ func InduceFailure(verb, resource string) clientgotesting.ReactionFunc {
 	}
 }
 
+// InduceConflictOnce is like InduceFailure, but only makes the first matching
+// call fail, with a conflict, as if another actor had updated the object
+// in the meantime.
+func InduceConflictOnce(verb, resource string) clientgotesting.ReactionFunc {
+	induced := false
+	return func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		if induced || !action.Matches(verb, resource) {
+			return false, nil, nil
+		}
+		induced = true
+		gr := schema.GroupResource{Group: action.GetResource().Group, Resource: resource}
+		return true, nil, apierrs.NewConflict(gr, "", fmt.Errorf("inducing conflict for %s %s", verb, resource))
+	}
+}
+
 func ValidateCreates(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
 	got := action.(clientgotesting.CreateAction).GetObject()
 	obj, ok := got.(apis.Validatable)
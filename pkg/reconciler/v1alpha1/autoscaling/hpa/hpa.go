@@ -155,6 +155,12 @@ func (c *Reconciler) reconcile(ctx context.Context, key string, pa *pav1alpha1.P
 		logger.Errorf("Error getting existing HPA %q: %v", desiredHpa.Name, err)
 		return err
 	} else {
+		if pa.ScaleDownDisabled() {
+			// The vendored autoscaling API has no scale-down policies, so we
+			// keep the HPA from scaling down by never letting its floor drop
+			// below the replicas it currently runs.
+			resources.RaiseMinReplicas(desiredHpa, hpa.Status.CurrentReplicas)
+		}
 		if !equality.Semantic.DeepEqual(desiredHpa.Spec, hpa.Spec) {
 			logger.Infof("Updating HPA %q", desiredHpa.Name)
 			if _, err := c.KubeClientSet.AutoscalingV1().HorizontalPodAutoscalers(pa.Namespace).Update(desiredHpa); err != nil {
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/knative/pkg/controller"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	autoscalingv1alpha1 "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
 	"github.com/knative/serving/pkg/reconciler"
 	"github.com/knative/serving/pkg/reconciler/v1alpha1/autoscaling/hpa/resources"
@@ -81,6 +82,35 @@ func TestReconcile(t *testing.T) {
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: hpa(testRevision, testNamespace, WithHPAClass, WithTargetAnnotation("1"), WithMetricAnnotation("cpu")),
 		}},
+	}, {
+		Name: "scale down disabled raises hpa floor",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithHPAClass, WithTraffic, withScaleDownDisabled("true")),
+			withCurrentReplicas(5)(hpa(testRevision, testNamespace, WithHPAClass,
+				WithMetricAnnotation("cpu"), withScaleDownDisabled("true"))),
+		},
+		Key: key(testRevision, testNamespace),
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withMinReplicas(5)(hpa(testRevision, testNamespace, WithHPAClass,
+				WithMetricAnnotation("cpu"), withScaleDownDisabled("true"))),
+		}},
+	}, {
+		Name: "scale down disabled keeps a higher hpa floor",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithHPAClass, WithTraffic, withScaleDownDisabled("true"),
+				withMinScale("7")),
+			withCurrentReplicas(7)(hpa(testRevision, testNamespace, WithHPAClass,
+				WithMetricAnnotation("cpu"), withScaleDownDisabled("true"), withMinScale("7"))),
+		},
+		Key: key(testRevision, testNamespace),
+	}, {
+		Name: "scale down enabled leaves hpa floor alone",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithHPAClass, WithTraffic, withScaleDownDisabled("false")),
+			withCurrentReplicas(5)(hpa(testRevision, testNamespace, WithHPAClass,
+				WithMetricAnnotation("cpu"), withScaleDownDisabled("false"))),
+		},
+		Key: key(testRevision, testNamespace),
 	}}
 
 	table.Test(t, MakeFactory(func(listers *Listers, opt reconciler.Options) controller.Reconciler {
@@ -120,3 +150,36 @@ func pa(name, namespace string, options ...PodAutoscalerOption) *autoscalingv1al
 func hpa(name, namespace string, options ...PodAutoscalerOption) *autoscalingv1.HorizontalPodAutoscaler {
 	return resources.MakeHPA(pa(name, namespace, options...))
 }
+
+func withAnnotation(k, v string) PodAutoscalerOption {
+	return func(pa *autoscalingv1alpha1.PodAutoscaler) {
+		if pa.Annotations == nil {
+			pa.Annotations = make(map[string]string)
+		}
+		pa.Annotations[k] = v
+	}
+}
+
+func withScaleDownDisabled(v string) PodAutoscalerOption {
+	return withAnnotation(autoscaling.ScaleDownDisabledAnnotationKey, v)
+}
+
+func withMinScale(v string) PodAutoscalerOption {
+	return withAnnotation(autoscaling.MinScaleAnnotationKey, v)
+}
+
+type hpaOption func(*autoscalingv1.HorizontalPodAutoscaler) *autoscalingv1.HorizontalPodAutoscaler
+
+func withCurrentReplicas(r int32) hpaOption {
+	return func(h *autoscalingv1.HorizontalPodAutoscaler) *autoscalingv1.HorizontalPodAutoscaler {
+		h.Status.CurrentReplicas = r
+		return h
+	}
+}
+
+func withMinReplicas(r int32) hpaOption {
+	return func(h *autoscalingv1.HorizontalPodAutoscaler) *autoscalingv1.HorizontalPodAutoscaler {
+		h.Spec.MinReplicas = &r
+		return h
+	}
+}
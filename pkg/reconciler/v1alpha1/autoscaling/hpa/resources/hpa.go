@@ -51,3 +51,18 @@ func MakeHPA(pa *v1alpha1.PodAutoscaler) *autoscalingv1.HorizontalPodAutoscaler
 	}
 	return hpa
 }
+
+// RaiseMinReplicas raises the floor of the given HPA to the provided number
+// of replicas, without ever going above its MaxReplicas. A floor already
+// higher than replicas is left untouched.
+func RaiseMinReplicas(hpa *autoscalingv1.HorizontalPodAutoscaler, replicas int32) {
+	if replicas > hpa.Spec.MaxReplicas {
+		replicas = hpa.Spec.MaxReplicas
+	}
+	if hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas >= replicas {
+		return
+	}
+	if replicas > 0 {
+		hpa.Spec.MinReplicas = &replicas
+	}
+}
@@ -213,6 +213,17 @@ func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler
 		return err
 	}
 
+	// Report how much of the scale is warm pool, i.e. on top of what the
+	// metrics asked for.
+	pa.Status.WarmPoolReplicas = 0
+	if desired := metric.Status.DesiredScale; desired >= 0 && want > desired {
+		warm := warmPoolSize(pa, c.dynConfig.Current())
+		if extra := want - desired; extra < warm {
+			warm = extra
+		}
+		pa.Status.WarmPoolReplicas = warm
+	}
+
 	// Compare the desired and observed resources to determine our situation.
 	got := 0
 
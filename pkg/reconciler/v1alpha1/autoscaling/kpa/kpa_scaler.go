@@ -98,6 +98,19 @@ func applyBounds(min, max int32) func(int32) int32 {
 	}
 }
 
+// warmPoolSize returns how many pre-started Pods the PA should keep on top of
+// its desired scale: the warmPool annotation capped by the cluster-wide
+// max-warm-pool-size.
+func warmPoolSize(pa *pav1alpha1.PodAutoscaler, config *autoscaler.Config) int32 {
+	if config == nil {
+		return 0
+	}
+	if warm := pa.WarmPool(); warm < config.MaxWarmPoolSize {
+		return warm
+	}
+	return config.MaxWarmPoolSize
+}
+
 // Scale attempts to scale the given PA's target reference to the desired scale.
 func (ks *kpaScaler) Scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, desiredScale int32) (int32, error) {
 	logger := logging.FromContext(ctx)
@@ -133,6 +146,15 @@ func (ks *kpaScaler) Scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, de
 		desiredScale = newScale
 	}
 
+	// Keep the warm pool on top of the desired scale. This also keeps the
+	// target from scaling to zero, which is what the warm pool is for.
+	if warm := warmPoolSize(pa, ks.getAutoscalerConfig()); warm > 0 && desiredScale >= 0 {
+		_, max := pa.ScaleBounds()
+		newScale := applyBounds(0, max)(desiredScale + warm)
+		logger.Debugf("Adding warm pool: %v -> %v", desiredScale, newScale)
+		desiredScale = newScale
+	}
+
 	if desiredScale == 0 {
 		// We should only scale to zero when both of the following conditions are true:
 		//   a) The PA has been active for atleast the stable window, after which it gets marked inactive
@@ -50,6 +50,7 @@ func TestKPAScaler(t *testing.T) {
 		scaleTo       int32
 		minScale      int32
 		maxScale      int32
+		warmPool      int32
 		wantReplicas  int
 		wantScaling   bool
 		kpaMutation   func(*pav1alpha1.PodAutoscaler)
@@ -151,6 +152,38 @@ func TestKPAScaler(t *testing.T) {
 		scaleTo:       -1,
 		wantReplicas:  12,
 		wantScaling:   false,
+	}, {
+		label:         "keeps warm pool on top of desired scale",
+		startReplicas: 1,
+		scaleTo:       5,
+		warmPool:      2,
+		wantReplicas:  7,
+		wantScaling:   true,
+	}, {
+		label:         "warm pool is capped by max-warm-pool-size",
+		startReplicas: 1,
+		scaleTo:       5,
+		warmPool:      10,
+		wantReplicas:  8,
+		wantScaling:   true,
+	}, {
+		label:         "warm pool is capped by maxScale",
+		startReplicas: 1,
+		scaleTo:       10,
+		maxScale:      11,
+		warmPool:      2,
+		wantReplicas:  11,
+		wantScaling:   true,
+	}, {
+		label:         "warm pool keeps pods after grace period",
+		startReplicas: 1,
+		scaleTo:       0,
+		warmPool:      2,
+		wantReplicas:  2,
+		wantScaling:   true,
+		kpaMutation: func(k *pav1alpha1.PodAutoscaler) {
+			kpaMarkInactive(k, time.Now().Add(-gracePeriod))
+		},
 	}}
 
 	for _, e := range examples {
@@ -164,6 +197,9 @@ func TestKPAScaler(t *testing.T) {
 			revisionScaler := NewKPAScaler(servingClient, scaleClient, TestLogger(t), newConfigWatcher())
 
 			pa := newKPA(t, servingClient, revision)
+			if e.warmPool > 0 {
+				pa.Annotations[autoscaling.WarmPoolAnnotationKey] = strconv.Itoa(int(e.warmPool))
+			}
 			if e.kpaMutation != nil {
 				e.kpaMutation(pa)
 			}
@@ -31,6 +31,7 @@ import (
 	fakeKna "github.com/knative/serving/pkg/client/clientset/versioned/fake"
 	informers "github.com/knative/serving/pkg/client/informers/externalversions"
 	"github.com/knative/serving/pkg/reconciler"
+	"github.com/knative/serving/pkg/reconciler/v1alpha1/autoscaling/kpa/resources"
 	revisionresources "github.com/knative/serving/pkg/reconciler/v1alpha1/revision/resources"
 	"github.com/knative/serving/pkg/system"
 	"go.uber.org/atomic"
@@ -53,6 +54,7 @@ var (
 		"panic-window":                            "10s",
 		"scale-to-zero-grace-period":              gracePeriod.String(),
 		"tick-interval":                           "2s",
+		"max-warm-pool-size":                      "3",
 	}
 )
 
@@ -603,6 +605,66 @@ func TestScaleFailure(t *testing.T) {
 	}
 }
 
+func TestWarmPoolStatus(t *testing.T) {
+	kubeClient := fakeK8s.NewSimpleClientset()
+	servingClient := fakeKna.NewSimpleClientset()
+
+	stopCh := make(chan struct{})
+	createdCh := make(chan struct{})
+	defer close(createdCh)
+
+	opts := reconciler.Options{
+		KubeClientSet:    kubeClient,
+		ServingClientSet: servingClient,
+		Logger:           TestLogger(t),
+	}
+
+	servingInformer := informers.NewSharedInformerFactory(servingClient, 0)
+	kubeInformer := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+
+	rev := newTestRevision(testNamespace, testRevision)
+	rev.Annotations = map[string]string{autoscaling.WarmPoolAnnotationKey: "2"}
+	scaleClient := &scalefake.FakeScaleClient{}
+	deployment := newDeployment(t, scaleClient, rev, 1)
+	kpaScaler := NewKPAScaler(servingClient, scaleClient, TestLogger(t), newConfigWatcher())
+
+	dynConfig := newDynamicConfig(t)
+	kpa := revisionresources.MakeKPA(rev)
+	kpa.SetDefaults()
+
+	// Pretend the metrics pipeline already asks for a single Pod.
+	fakeMetrics := newTestKPAMetrics(createdCh, stopCh)
+	fakeMetrics.metric = resources.MakeMetric(context.TODO(), kpa, dynConfig.Current())
+	fakeMetrics.metric.Status.DesiredScale = 1
+
+	ctl := NewController(&opts,
+		servingInformer.Autoscaling().V1alpha1().PodAutoscalers(),
+		kubeInformer.Core().V1().Endpoints(),
+		fakeMetrics,
+		kpaScaler,
+		dynConfig,
+	)
+
+	servingClient.AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(kpa)
+	servingInformer.Autoscaling().V1alpha1().PodAutoscalers().Informer().GetIndexer().Add(kpa)
+
+	if err := ctl.Reconciler.Reconcile(context.TODO(), testNamespace+"/"+testRevision); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+
+	// The single desired Pod plus the two warm ones.
+	checkReplicas(t, scaleClient, deployment, 3)
+
+	newKPA, err := servingClient.AutoscalingV1alpha1().PodAutoscalers(kpa.Namespace).Get(
+		kpa.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got, want := newKPA.Status.WarmPoolReplicas, int32(2); got != want {
+		t.Errorf("Status.WarmPoolReplicas = %d, wanted %d", got, want)
+	}
+}
+
 func TestBadKey(t *testing.T) {
 	kubeClient := fakeK8s.NewSimpleClientset()
 	servingClient := fakeKna.NewSimpleClientset()
@@ -43,10 +43,14 @@ func MakeMetric(ctx context.Context, pa *v1alpha1.PodAutoscaler, config *autosca
 			target = annotationTarget
 		}
 	}
+	// The tick interval is left unset unless overridden, so that the
+	// autoscaler falls back to its configured tick-interval.
+	tickInterval, _ := pa.TickInterval()
 	return &autoscaler.Metric{
 		ObjectMeta: pa.ObjectMeta,
 		Spec: autoscaler.MetricSpec{
 			TargetConcurrency: target,
+			TickInterval:      tickInterval,
 		},
 	}
 }
@@ -55,6 +55,10 @@ func TestMakeMetric(t *testing.T) {
 		name: "with target annotation greater than container concurrency (ignore annotation for safety)",
 		pa:   pa(WithContainerConcurrency(1), WithTargetAnnotation("10")),
 		want: metric(withTarget(1.0), withTargetAnnotation("10")),
+	}, {
+		name: "with tick interval annotation",
+		pa:   pa(WithTickIntervalAnnotation("500ms")),
+		want: metric(withTickInterval(500*time.Millisecond), withTickIntervalAnnotation("500ms")),
 	}}
 
 	for _, tc := range cases {
@@ -119,6 +123,18 @@ func withTargetAnnotation(target string) MetricOption {
 	}
 }
 
+func withTickInterval(tickInterval time.Duration) MetricOption {
+	return func(metric *autoscaler.Metric) {
+		metric.Spec.TickInterval = tickInterval
+	}
+}
+
+func withTickIntervalAnnotation(tickInterval string) MetricOption {
+	return func(metric *autoscaler.Metric) {
+		metric.Annotations[autoscaling.TickIntervalAnnotationKey] = tickInterval
+	}
+}
+
 var config = &autoscaler.Config{
 	EnableScaleToZero:                    true,
 	ContainerConcurrencyTargetPercentage: 1.0,
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/reconciler"
 )
 
 // DeprecatedRevision produces revision name in the format
@@ -27,8 +28,11 @@ import (
 //
 // This should eventually change to something like
 // '{config-name}-{config.metadata.generation}'
+//
+// Revision names must fit in a DNS label, so the names of Configurations too
+// long for that are shortened with a hash, see reconciler.ChildName.
 func DeprecatedRevision(config *v1alpha1.Configuration) string {
-	return fmt.Sprintf("%s-%05d", config.Name, config.Spec.Generation)
+	return reconciler.ChildName(config.Name, fmt.Sprintf("-%05d", config.Spec.Generation))
 }
 
 // DeprecatedBuild produces build name in the format
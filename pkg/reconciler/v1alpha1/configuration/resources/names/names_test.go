@@ -17,9 +17,11 @@ limitations under the License.
 package names
 
 import (
+	"strings"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	buildv1alpha1 "github.com/knative/build/pkg/apis/build/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
@@ -97,3 +99,25 @@ func TestNamer(t *testing.T) {
 		})
 	}
 }
+
+func TestRevisionNamesOfLongConfigurationsFit(t *testing.T) {
+	// The longest Configuration names validation allows, differing only at the end.
+	a := &v1alpha1.Configuration{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 62) + "a"},
+		Spec:       v1alpha1.ConfigurationSpec{Generation: 1},
+	}
+	b := &v1alpha1.Configuration{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 62) + "b"},
+		Spec:       v1alpha1.ConfigurationSpec{Generation: 1},
+	}
+	got := DeprecatedRevision(a)
+	if errs := validation.IsDNS1035Label(got); len(errs) != 0 {
+		t.Errorf("DeprecatedRevision(%q) = %q, not a DNS label: %v", a.Name, got, errs)
+	}
+	if !strings.HasSuffix(got, "-00001") {
+		t.Errorf("DeprecatedRevision(%q) = %q, want the generation suffix", a.Name, got)
+	}
+	if got == DeprecatedRevision(b) {
+		t.Errorf("DeprecatedRevision(%q) = DeprecatedRevision(%q) = %q", a.Name, b.Name, got)
+	}
+}
@@ -18,9 +18,13 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
@@ -28,11 +32,33 @@ const (
 
 	queueSidecarImageKey           = "queueSidecarImage"
 	registriesSkippingTagResolving = "registriesSkippingTagResolving"
+	createServiceBeforeDeployment  = "createServiceBeforeDeployment"
+	imagePullRetryPeriodKey        = "imagePullRetryPeriod"
+	debugSidecarImageKey           = "debugSidecarImage"
+	singleConcurrencyProbeTimeout  = "singleConcurrencyProbeTimeoutSeconds"
+	imageSizeThresholdKey          = "imageSizeThresholdBytes"
+	modelLoaderImageKey            = "modelLoaderImage"
+	maxReconcileRetriesKey         = "maxReconcileRetries"
+	userContainerMemoryLimitKey    = "userContainerMemoryLimit"
+	userContainerStorageRequestKey = "userContainerEphemeralStorageRequest"
+	minReadySecondsKey             = "minReadySeconds"
+	defaultReadinessProbeKey       = "defaultReadinessProbe"
+
+	// DefaultImagePullRetryPeriod is how long we wait before checking
+	// again on a Revision whose image could not be pulled.
+	DefaultImagePullRetryPeriod = 30 * time.Second
+
+	// DefaultMaxReconcileRetries is how many times in a row a Revision
+	// failing to reconcile is retried before we give up on it.
+	DefaultMaxReconcileRetries = 5
 )
 
 // NewControllerConfigFromMap creates a Controller from the supplied Map
 func NewControllerConfigFromMap(configMap map[string]string) (*Controller, error) {
-	nc := &Controller{}
+	nc := &Controller{
+		ImagePullRetryPeriod: DefaultImagePullRetryPeriod,
+		MaxReconcileRetries:  DefaultMaxReconcileRetries,
+	}
 
 	if qsideCarImage, ok := configMap[queueSidecarImageKey]; !ok {
 		return nil, errors.New("Queue sidecar image is missing")
@@ -46,6 +72,87 @@ func NewControllerConfigFromMap(configMap map[string]string) (*Controller, error
 	} else {
 		nc.RegistriesSkippingTagResolving = toStringSet(registries, ",")
 	}
+
+	if sbd, ok := configMap[createServiceBeforeDeployment]; ok {
+		nc.CreateServiceBeforeDeployment = strings.ToLower(sbd) == "true"
+	}
+
+	if drp, ok := configMap[defaultReadinessProbeKey]; ok {
+		nc.DefaultReadinessProbe = strings.ToLower(drp) == "true"
+	}
+
+	nc.DebugSidecarImage = configMap[debugSidecarImageKey]
+	nc.ModelLoaderImage = configMap[modelLoaderImageKey]
+
+	if raw, ok := configMap[singleConcurrencyProbeTimeout]; ok {
+		if val, err := strconv.ParseInt(raw, 10, 32); err != nil {
+			return nil, err
+		} else if val < 0 {
+			return nil, fmt.Errorf("%s must not be negative, was: %d", singleConcurrencyProbeTimeout, val)
+		} else {
+			nc.SingleConcurrencyProbeTimeoutSeconds = int32(val)
+		}
+	}
+
+	if raw, ok := configMap[imageSizeThresholdKey]; ok {
+		if val, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, err
+		} else if val < 0 {
+			return nil, fmt.Errorf("%s must not be negative, was: %d", imageSizeThresholdKey, val)
+		} else {
+			nc.ImageSizeThresholdBytes = val
+		}
+	}
+
+	if raw, ok := configMap[imagePullRetryPeriodKey]; ok {
+		if val, err := time.ParseDuration(raw); err != nil {
+			return nil, err
+		} else if val <= 0 {
+			return nil, fmt.Errorf("%s must be positive, was: %v", imagePullRetryPeriodKey, val)
+		} else {
+			nc.ImagePullRetryPeriod = val
+		}
+	}
+
+	if raw, ok := configMap[maxReconcileRetriesKey]; ok {
+		if val, err := strconv.Atoi(raw); err != nil {
+			return nil, err
+		} else if val < 0 {
+			return nil, fmt.Errorf("%s must not be negative, was: %d", maxReconcileRetriesKey, val)
+		} else {
+			nc.MaxReconcileRetries = val
+		}
+	}
+
+	if raw, ok := configMap[userContainerMemoryLimitKey]; ok && raw != "" {
+		if val, err := resource.ParseQuantity(raw); err != nil {
+			return nil, err
+		} else if val.Sign() <= 0 {
+			return nil, fmt.Errorf("%s must be positive, was: %v", userContainerMemoryLimitKey, raw)
+		} else {
+			nc.UserContainerMemoryLimit = &val
+		}
+	}
+
+	if raw, ok := configMap[minReadySecondsKey]; ok {
+		if val, err := strconv.ParseInt(raw, 10, 32); err != nil {
+			return nil, err
+		} else if val < 0 {
+			return nil, fmt.Errorf("%s must not be negative, was: %d", minReadySecondsKey, val)
+		} else {
+			nc.MinReadySeconds = int32(val)
+		}
+	}
+
+	if raw, ok := configMap[userContainerStorageRequestKey]; ok && raw != "" {
+		if val, err := resource.ParseQuantity(raw); err != nil {
+			return nil, err
+		} else if val.Sign() <= 0 {
+			return nil, fmt.Errorf("%s must be positive, was: %v", userContainerStorageRequestKey, raw)
+		} else {
+			nc.UserContainerEphemeralStorageRequest = &val
+		}
+	}
 	return nc, nil
 }
 
@@ -72,4 +179,61 @@ type Controller struct {
 
 	// Repositories for which tag to digest resolving should be skipped
 	RegistriesSkippingTagResolving map[string]struct{}
+
+	// CreateServiceBeforeDeployment controls the order in which the
+	// Revision's K8s Service and Deployment are reconciled. By default
+	// the Deployment is reconciled first, so the Service never exists
+	// without any pods to back it.
+	CreateServiceBeforeDeployment bool
+
+	// ImagePullRetryPeriod is how long to wait before re-reconciling a
+	// Revision whose pods are failing to pull the user container image.
+	ImagePullRetryPeriod time.Duration
+
+	// DebugSidecarImage is the image of the debug sidecar injected into the
+	// pods of Revisions that request it. Leaving it empty disables injection.
+	DebugSidecarImage string
+
+	// SingleConcurrencyProbeTimeoutSeconds is the timeout applied to the
+	// readiness probe of Revisions with a ContainerConcurrency of 1 when
+	// the user has not set one. HTTP readiness probes go through the
+	// queue-proxy, where they wait behind the single in-flight request.
+	// Zero keeps the Kubernetes default.
+	SingleConcurrencyProbeTimeoutSeconds int32
+
+	// ImageSizeThresholdBytes is the size above which the user container
+	// image of a Revision is reported with an event and condition once it
+	// has been pulled.
+	// Zero disables the check.
+	ImageSizeThresholdBytes int64
+
+	// ModelLoaderImage is the image of the init container that loads the
+	// model of Revisions that declare a model source. Leaving it empty
+	// disables model loading.
+	ModelLoaderImage string
+
+	// MaxReconcileRetries is how many times in a row a Revision failing to
+	// reconcile is retried before it is dropped from the work queue until
+	// it, or one of its resources, changes again. Zero retries forever.
+	MaxReconcileRetries int
+
+	// UserContainerMemoryLimit is the memory limit given to the user
+	// container of Revisions that do not set one themselves. Nil leaves
+	// such containers without a memory limit.
+	UserContainerMemoryLimit *resource.Quantity
+
+	// UserContainerEphemeralStorageRequest is the ephemeral-storage request
+	// given to the user container of Revisions that do not set one
+	// themselves. Nil leaves such containers without a request.
+	UserContainerEphemeralStorageRequest *resource.Quantity
+
+	// MinReadySeconds is how long a new pod of a Revision must be ready
+	// before it counts as available, both for its Deployment and for the
+	// Revision to become Ready. Zero counts pods as soon as they are ready.
+	MinReadySeconds int32
+
+	// DefaultReadinessProbe gives the user container of Revisions that do
+	// not declare a readiness probe a TCP probe against the serving port,
+	// so traffic isn't routed to pods before the app is listening.
+	DefaultReadinessProbe bool
 }
@@ -18,10 +18,12 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/knative/serving/pkg/system"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	. "github.com/knative/serving/pkg/reconciler/testing"
@@ -32,12 +34,23 @@ var noSidecarImage = ""
 func TestControllerConfigurationFromFile(t *testing.T) {
 	cm := ConfigMapFromTestFile(t, ControllerConfigName)
 
-	if _, err := NewControllerConfigFromConfigMap(cm); err != nil {
-		t.Errorf("NewControllerConfigFromConfigMap() = %v", err)
+	cc, err := NewControllerConfigFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("NewControllerConfigFromConfigMap() = %v", err)
+	}
+	// A generous memory limit is defaulted.
+	if got, want := cc.UserContainerMemoryLimit, resource.MustParse("2Gi"); got == nil || got.Cmp(want) != 0 {
+		t.Errorf("UserContainerMemoryLimit = %v, want %v", got, want.String())
+	}
+	// A small ephemeral-storage request is defaulted.
+	if got, want := cc.UserContainerEphemeralStorageRequest, resource.MustParse("50Mi"); got == nil || got.Cmp(want) != 0 {
+		t.Errorf("UserContainerEphemeralStorageRequest = %v, want %v", got, want.String())
 	}
 }
 
 func TestControllerConfiguration(t *testing.T) {
+	memoryLimit := resource.MustParse("1Gi")
+	storageRequest := resource.MustParse("50Mi")
 	configTests := []struct {
 		name           string
 		wantErr        bool
@@ -51,7 +64,9 @@ func TestControllerConfiguration(t *testing.T) {
 				"ko.local": {},
 				"":         {},
 			},
-			QueueSidecarImage: noSidecarImage,
+			QueueSidecarImage:    noSidecarImage,
+			ImagePullRetryPeriod: DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:  DefaultMaxReconcileRetries,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -70,7 +85,9 @@ func TestControllerConfiguration(t *testing.T) {
 				"ko.dev":   {},
 				"ko.local": {},
 			},
-			QueueSidecarImage: noSidecarImage,
+			QueueSidecarImage:    noSidecarImage,
+			ImagePullRetryPeriod: DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:  DefaultMaxReconcileRetries,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -82,6 +99,322 @@ func TestControllerConfiguration(t *testing.T) {
 				registriesSkippingTagResolving: "ko.local,ko.dev",
 			},
 		},
+	}, {
+		name:    "controller configuration with service before deployment",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			CreateServiceBeforeDeployment:  true,
+			ImagePullRetryPeriod:           DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:            DefaultMaxReconcileRetries,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:          noSidecarImage,
+				createServiceBeforeDeployment: "true",
+			},
+		},
+	}, {
+		name:    "controller configuration with default readiness probe",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			DefaultReadinessProbe:          true,
+			ImagePullRetryPeriod:           DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:            DefaultMaxReconcileRetries,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:     noSidecarImage,
+				defaultReadinessProbeKey: "true",
+			},
+		},
+	}, {
+		name:    "controller configuration with image pull retry period",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			ImagePullRetryPeriod:           5 * time.Second,
+			MaxReconcileRetries:            DefaultMaxReconcileRetries,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:    noSidecarImage,
+				imagePullRetryPeriodKey: "5s",
+			},
+		},
+	}, {
+		name:    "controller configuration with debug sidecar image",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			ImagePullRetryPeriod:           DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:            DefaultMaxReconcileRetries,
+			DebugSidecarImage:              "busybox",
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey: noSidecarImage,
+				debugSidecarImageKey: "busybox",
+			},
+		},
+	}, {
+		name:    "controller configuration with single concurrency probe timeout",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving:       map[string]struct{}{},
+			QueueSidecarImage:                    noSidecarImage,
+			ImagePullRetryPeriod:                 DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:                  DefaultMaxReconcileRetries,
+			SingleConcurrencyProbeTimeoutSeconds: 10,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:          noSidecarImage,
+				singleConcurrencyProbeTimeout: "10",
+			},
+		},
+	}, {
+		name:           "controller with negative single concurrency probe timeout",
+		wantErr:        true,
+		wantController: (*Controller)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:          noSidecarImage,
+				singleConcurrencyProbeTimeout: "-1",
+			},
+		},
+	}, {
+		name:    "controller configuration with image size threshold",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			ImagePullRetryPeriod:           DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:            DefaultMaxReconcileRetries,
+			ImageSizeThresholdBytes:        1 << 30,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:  noSidecarImage,
+				imageSizeThresholdKey: "1073741824",
+			},
+		},
+	}, {
+		name:    "controller configuration with model loader image",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			ImagePullRetryPeriod:           DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:            DefaultMaxReconcileRetries,
+			ModelLoaderImage:               "loader",
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey: noSidecarImage,
+				modelLoaderImageKey:  "loader",
+			},
+		},
+	}, {
+		name:    "controller configuration with user container memory limit",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			ImagePullRetryPeriod:           DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:            DefaultMaxReconcileRetries,
+			UserContainerMemoryLimit:       &memoryLimit,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:        noSidecarImage,
+				userContainerMemoryLimitKey: "1Gi",
+			},
+		},
+	}, {
+		name:           "controller configuration with invalid user container memory limit",
+		wantErr:        true,
+		wantController: (*Controller)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:        noSidecarImage,
+				userContainerMemoryLimitKey: "lots",
+			},
+		},
+	}, {
+		name:    "controller configuration with user container ephemeral storage request",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving:       map[string]struct{}{},
+			QueueSidecarImage:                    noSidecarImage,
+			ImagePullRetryPeriod:                 DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:                  DefaultMaxReconcileRetries,
+			UserContainerEphemeralStorageRequest: &storageRequest,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:           noSidecarImage,
+				userContainerStorageRequestKey: "50Mi",
+			},
+		},
+	}, {
+		name:           "controller configuration with negative user container ephemeral storage request",
+		wantErr:        true,
+		wantController: (*Controller)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:           noSidecarImage,
+				userContainerStorageRequestKey: "-1Mi",
+			},
+		},
+	}, {
+		name:    "controller configuration with min ready seconds",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			ImagePullRetryPeriod:           DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:            DefaultMaxReconcileRetries,
+			MinReadySeconds:                10,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey: noSidecarImage,
+				minReadySecondsKey:   "10",
+			},
+		},
+	}, {
+		name:           "controller configuration with negative min ready seconds",
+		wantErr:        true,
+		wantController: (*Controller)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey: noSidecarImage,
+				minReadySecondsKey:   "-1",
+			},
+		},
+	}, {
+		name:    "controller configuration with max reconcile retries",
+		wantErr: false,
+		wantController: &Controller{
+			RegistriesSkippingTagResolving: map[string]struct{}{},
+			QueueSidecarImage:              noSidecarImage,
+			ImagePullRetryPeriod:           DefaultImagePullRetryPeriod,
+			MaxReconcileRetries:            0,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:   noSidecarImage,
+				maxReconcileRetriesKey: "0",
+			},
+		},
+	}, {
+		name:           "controller with negative max reconcile retries",
+		wantErr:        true,
+		wantController: (*Controller)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:   noSidecarImage,
+				maxReconcileRetriesKey: "-1",
+			},
+		},
+	}, {
+		name:           "controller with bad image pull retry period",
+		wantErr:        true,
+		wantController: (*Controller)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:    noSidecarImage,
+				imagePullRetryPeriodKey: "soon",
+			},
+		},
+	}, {
+		name:           "controller with non-positive image pull retry period",
+		wantErr:        true,
+		wantController: (*Controller)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ControllerConfigName,
+			},
+			Data: map[string]string{
+				queueSidecarImageKey:    noSidecarImage,
+				imagePullRetryPeriodKey: "0s",
+			},
+		},
 	}, {
 		name:           "controller with no side car image",
 		wantErr:        true,
@@ -95,6 +428,11 @@ func TestControllerConfiguration(t *testing.T) {
 		},
 	}}
 
+	// Quantities hold unexported fields, compare them by value instead.
+	quantityComparer := cmp.Comparer(func(a, b resource.Quantity) bool {
+		return a.Cmp(b) == 0
+	})
+
 	for _, tt := range configTests {
 		actualController, err := NewControllerConfigFromConfigMap(tt.config)
 
@@ -102,7 +440,7 @@ func TestControllerConfiguration(t *testing.T) {
 			t.Fatalf("Test: %q; NewControllerConfigFromConfigMap() error = %v, WantErr %v", tt.name, err, tt.wantErr)
 		}
 
-		if diff := cmp.Diff(actualController, tt.wantController); diff != "" {
+		if diff := cmp.Diff(actualController, tt.wantController, quantityComparer); diff != "" {
 			t.Fatalf("Test: %q; want %v, but got %v", tt.name, tt.wantController, actualController)
 		}
 	}
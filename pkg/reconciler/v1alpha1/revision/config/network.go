@@ -20,13 +20,14 @@ import (
 	"net"
 	"strings"
 
+	apisconfig "github.com/knative/serving/pkg/apis/config"
 	corev1 "k8s.io/api/core/v1"
 )
 
 const (
 	// NetworkConfigName is the name of the configmap containing all
 	// customizations for networking features.
-	NetworkConfigName = "config-network"
+	NetworkConfigName = apisconfig.NetworkConfigName
 
 	// IstioOutboundIPRangesKey is the name of the configuration entry
 	// that specifies Istio outbound ip ranges.
@@ -39,6 +40,10 @@ type Network struct {
 	// IstioOutboundIPRange specifies the IP ranges to intercept
 	// by Istio sidecar.
 	IstioOutboundIPRanges string
+
+	// QueueProxyPorts are the ports the queue-proxy sidecar listens on,
+	// which the webhook keeps user containers from declaring.
+	QueueProxyPorts apisconfig.QueueProxyPorts
 }
 
 func validateAndNormalizeOutboundIPRanges(s string) (string, error) {
@@ -68,7 +73,11 @@ func validateAndNormalizeOutboundIPRanges(s string) (string, error) {
 
 // NewNetworkFromConfigMap creates a Network from the supplied ConfigMap
 func NewNetworkFromConfigMap(configMap *corev1.ConfigMap) (*Network, error) {
-	nc := &Network{}
+	qp, err := apisconfig.NewQueueProxyPortsFromConfigMap(configMap)
+	if err != nil {
+		return nil, err
+	}
+	nc := &Network{QueueProxyPorts: *qp}
 	if ipr, ok := configMap.Data[IstioOutboundIPRangesKey]; !ok {
 		// It is OK for this to be absent, we will elide the annotation.
 	} else if normalizedIpr, err := validateAndNormalizeOutboundIPRanges(ipr); err != nil {
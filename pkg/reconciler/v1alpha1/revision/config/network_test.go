@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	apisconfig "github.com/knative/serving/pkg/apis/config"
 	"github.com/knative/serving/pkg/system"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -27,6 +28,12 @@ import (
 	. "github.com/knative/serving/pkg/reconciler/testing"
 )
 
+var defaultQueueProxyPorts = apisconfig.QueueProxyPorts{
+	Serving: apisconfig.DefaultQueueServingPort,
+	Admin:   apisconfig.DefaultQueueAdminPort,
+	Metrics: apisconfig.DefaultQueueMetricsPort,
+}
+
 func TestOurNetwork(t *testing.T) {
 	cm := ConfigMapFromTestFile(t, NetworkConfigName)
 
@@ -44,7 +51,7 @@ func TestNetworkConfiguration(t *testing.T) {
 	}{{
 		name:           "network configuration with no network input",
 		wantErr:        false,
-		wantController: &Network{},
+		wantController: &Network{QueueProxyPorts: defaultQueueProxyPorts},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: system.Namespace,
@@ -65,7 +72,7 @@ func TestNetworkConfiguration(t *testing.T) {
 		}}, {
 		name:           "network configuration with empty network",
 		wantErr:        false,
-		wantController: &Network{},
+		wantController: &Network{QueueProxyPorts: defaultQueueProxyPorts},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: system.Namespace,
@@ -137,7 +144,7 @@ func TestNetworkConfiguration(t *testing.T) {
 		}}, {
 		name:           "network configuration with invalid network string",
 		wantErr:        false,
-		wantController: &Network{},
+		wantController: &Network{QueueProxyPorts: defaultQueueProxyPorts},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: system.Namespace,
@@ -149,7 +156,7 @@ func TestNetworkConfiguration(t *testing.T) {
 		}}, {
 		name:           "network configuration with invalid network string",
 		wantErr:        false,
-		wantController: &Network{},
+		wantController: &Network{QueueProxyPorts: defaultQueueProxyPorts},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: system.Namespace,
@@ -161,7 +168,7 @@ func TestNetworkConfiguration(t *testing.T) {
 		}}, {
 		name:           "network configuration with invalid network range",
 		wantErr:        false,
-		wantController: &Network{},
+		wantController: &Network{QueueProxyPorts: defaultQueueProxyPorts},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: system.Namespace,
@@ -175,6 +182,7 @@ func TestNetworkConfiguration(t *testing.T) {
 		wantErr: false,
 		wantController: &Network{
 			IstioOutboundIPRanges: "10.10.10.0/24",
+			QueueProxyPorts:       defaultQueueProxyPorts,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -189,6 +197,7 @@ func TestNetworkConfiguration(t *testing.T) {
 		wantErr: false,
 		wantController: &Network{
 			IstioOutboundIPRanges: "10.10.10.0/24,10.240.10.0/14,192.192.10.0/16",
+			QueueProxyPorts:       defaultQueueProxyPorts,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -203,6 +212,7 @@ func TestNetworkConfiguration(t *testing.T) {
 		wantErr: false,
 		wantController: &Network{
 			IstioOutboundIPRanges: "*",
+			QueueProxyPorts:       defaultQueueProxyPorts,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -212,6 +222,38 @@ func TestNetworkConfiguration(t *testing.T) {
 			Data: map[string]string{
 				IstioOutboundIPRangesKey: "*",
 			},
+		}}, {
+		name:    "network configuration with queue-proxy ports",
+		wantErr: false,
+		wantController: &Network{
+			QueueProxyPorts: apisconfig.QueueProxyPorts{
+				Serving: 18012,
+				Admin:   18022,
+				Metrics: 19090,
+			},
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      NetworkConfigName,
+			},
+			Data: map[string]string{
+				"queueProxy.servingPort": "18012",
+				"queueProxy.adminPort":   "18022",
+				"queueProxy.metricsPort": "19090",
+			},
+		}}, {
+		name:           "network configuration with invalid queue-proxy port",
+		wantErr:        true,
+		wantController: (*Network)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      NetworkConfigName,
+			},
+			Data: map[string]string{
+				"queueProxy.adminPort": "not a port",
+			},
 		}},
 	}
 
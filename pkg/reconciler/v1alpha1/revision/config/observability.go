@@ -46,6 +46,11 @@ type Observability struct {
 	// LoggingURLTemplate is a string containing the logging url template where
 	// the variable REVISION_UID will be replaced with the created revision's UID.
 	LoggingURLTemplate string
+
+	// EnablePrometheusScrapeAnnotations specifies whether to annotate the
+	// revision pods with prometheus.io/scrape, /port and /path, pointing at
+	// the queue-proxy metrics, for annotation-based Prometheus discovery.
+	EnablePrometheusScrapeAnnotations bool
 }
 
 // NewObservabilityFromConfigMap creates a Observability from the supplied ConfigMap
@@ -60,11 +65,18 @@ func NewObservabilityFromConfigMap(configMap *corev1.ConfigMap) (*Observability,
 		return nil, fmt.Errorf("Received bad Observability ConfigMap, want %q when %q is true",
 			"logging.fluentd-sidecar-image", "logging.enable-var-log-collection")
 	}
-	if fsoc, ok := configMap.Data["logging.fluentd-sidecar-output-config"]; ok {
+	if fsoc, ok := configMap.Data["logging.fluentd-sidecar-output-config"]; ok && strings.TrimSpace(fsoc) != "" {
 		oc.FluentdSidecarOutputConfig = fsoc
+	} else if oc.EnableVarLogCollection {
+		// Without an output the sidecar collects the logs but ships them nowhere.
+		return nil, fmt.Errorf("Received bad Observability ConfigMap, want a non-empty %q when %q is true",
+			"logging.fluentd-sidecar-output-config", "logging.enable-var-log-collection")
 	}
 	if rut, ok := configMap.Data["logging.revision-url-template"]; ok {
 		oc.LoggingURLTemplate = rut
 	}
+	if epsa, ok := configMap.Data["metrics.enable-prometheus-scrape-annotations"]; ok {
+		oc.EnablePrometheusScrapeAnnotations = strings.ToLower(epsa) == "true"
+	}
 	return oc, nil
 }
@@ -45,10 +45,11 @@ func TestObservabilityConfiguration(t *testing.T) {
 		name:    "observability configuration with all inputs",
 		wantErr: false,
 		wantController: &Observability{
-			LoggingURLTemplate:         "https://logging.io",
-			FluentdSidecarOutputConfig: "the-config",
-			FluentdSidecarImage:        "gcr.io/log-stuff/fluentd:latest",
-			EnableVarLogCollection:     true,
+			LoggingURLTemplate:                "https://logging.io",
+			FluentdSidecarOutputConfig:        "the-config",
+			FluentdSidecarImage:               "gcr.io/log-stuff/fluentd:latest",
+			EnableVarLogCollection:            true,
+			EnablePrometheusScrapeAnnotations: true,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -56,10 +57,11 @@ func TestObservabilityConfiguration(t *testing.T) {
 				Name:      ObservabilityConfigName,
 			},
 			Data: map[string]string{
-				"logging.enable-var-log-collection":     "true",
-				"logging.fluentd-sidecar-image":         "gcr.io/log-stuff/fluentd:latest",
-				"logging.fluentd-sidecar-output-config": "the-config",
-				"logging.revision-url-template":         "https://logging.io",
+				"logging.enable-var-log-collection":            "true",
+				"logging.fluentd-sidecar-image":                "gcr.io/log-stuff/fluentd:latest",
+				"logging.fluentd-sidecar-output-config":        "the-config",
+				"logging.revision-url-template":                "https://logging.io",
+				"metrics.enable-prometheus-scrape-annotations": "true",
 			},
 		},
 	}, {
@@ -88,6 +90,35 @@ func TestObservabilityConfiguration(t *testing.T) {
 				"logging.enable-var-log-collection": "true",
 			},
 		},
+	}, {
+		name:           "observability configuration with no side car output config",
+		wantErr:        true,
+		wantController: (*Observability)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ObservabilityConfigName,
+			},
+			Data: map[string]string{
+				"logging.enable-var-log-collection": "true",
+				"logging.fluentd-sidecar-image":     "gcr.io/log-stuff/fluentd:latest",
+			},
+		},
+	}, {
+		name:           "observability configuration with blank side car output config",
+		wantErr:        true,
+		wantController: (*Observability)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace,
+				Name:      ObservabilityConfigName,
+			},
+			Data: map[string]string{
+				"logging.enable-var-log-collection":     "true",
+				"logging.fluentd-sidecar-image":         "gcr.io/log-stuff/fluentd:latest",
+				"logging.fluentd-sidecar-output-config": "  \n",
+			},
+		},
 	}}
 
 	for _, tt := range observabilityConfigTests {
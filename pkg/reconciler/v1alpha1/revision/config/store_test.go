@@ -24,6 +24,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/knative/serving/pkg/autoscaler"
 	"github.com/knative/serving/pkg/logging"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	. "github.com/knative/serving/pkg/reconciler/v1alpha1/testing"
 )
@@ -47,7 +48,10 @@ func TestStoreLoadWithContext(t *testing.T) {
 
 	t.Run("controller", func(t *testing.T) {
 		expected, _ := NewControllerConfigFromConfigMap(controllerConfig)
-		if diff := cmp.Diff(expected, config.Controller); diff != "" {
+		quantityComparer := cmp.Comparer(func(x, y resource.Quantity) bool {
+			return x.Cmp(y) == 0
+		})
+		if diff := cmp.Diff(expected, config.Controller, quantityComparer); diff != "" {
 			t.Errorf("Unexpected controller config (-want, +got): %v", diff)
 		}
 	})
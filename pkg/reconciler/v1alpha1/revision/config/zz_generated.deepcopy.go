@@ -30,6 +30,16 @@ func (in *Controller) DeepCopyInto(out *Controller) {
 			(*out)[key] = struct{}{}
 		}
 	}
+	if in.UserContainerMemoryLimit != nil {
+		in, out := &in.UserContainerMemoryLimit, &out.UserContainerMemoryLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.UserContainerEphemeralStorageRequest != nil {
+		in, out := &in.UserContainerEphemeralStorageRequest, &out.UserContainerEphemeralStorageRequest
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	return
 }
 
@@ -46,6 +56,7 @@ func (in *Controller) DeepCopy() *Controller {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
+	out.QueueProxyPorts = in.QueueProxyPorts
 	return
 }
 
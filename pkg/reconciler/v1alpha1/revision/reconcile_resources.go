@@ -19,17 +19,21 @@ package revision
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/knative/pkg/kmp"
 	"github.com/knative/pkg/logging"
 	"github.com/knative/pkg/logging/logkey"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	kpav1alpha1 "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/reconciler/v1alpha1/revision/config"
 	"github.com/knative/serving/pkg/reconciler/v1alpha1/revision/resources"
 	resourcenames "github.com/knative/serving/pkg/reconciler/v1alpha1/revision/resources/names"
 	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
@@ -52,17 +56,29 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1alpha1.Revi
 		deployment, err = c.createDeployment(ctx, rev)
 		if err != nil {
 			logger.Errorf("Error creating deployment %q: %v", deploymentName, err)
+			c.Recorder.Eventf(rev, corev1.EventTypeWarning, "FailedCreateDeployment",
+				"Failed to create Deployment %q: %v", deploymentName, err)
+			rev.Status.MarkResourceFailed("Deployment", deploymentName, err.Error())
 			return err
 		}
 		logger.Infof("Created deployment %q", deploymentName)
+		c.Recorder.Eventf(rev, corev1.EventTypeNormal, "CreatedDeployment",
+			"Created Deployment %q", deploymentName)
 	} else if err != nil {
 		logger.Errorf("Error reconciling deployment %q: %v", deploymentName, err)
 		return err
+	} else if !metav1.IsControlledBy(deployment, rev) {
+		// Surface an error in the revision's status, and return an error.
+		rev.Status.MarkResourceNotOwned("Deployment", deploymentName)
+		return fmt.Errorf("Revision: %q does not own Deployment: %q", rev.Name, deploymentName)
 	} else {
 		// The deployment exists, but make sure that it has the shape that we expect.
 		deployment, _, err = c.checkAndUpdateDeployment(ctx, rev, deployment)
 		if err != nil {
 			logger.Errorf("Error updating deployment %q: %v", deploymentName, err)
+			c.Recorder.Eventf(rev, corev1.EventTypeWarning, "FailedUpdateDeployment",
+				"Failed to update Deployment %q: %v", deploymentName, err)
+			rev.Status.MarkResourceFailed("Deployment", deploymentName, err.Error())
 			return err
 		}
 	}
@@ -78,7 +94,17 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1alpha1.Revi
 
 			for _, status := range pod.Status.ContainerStatuses {
 				if status.Name == resources.UserContainerName {
-					if t := status.LastTerminationState.Terminated; t != nil {
+					if w := status.State.Waiting; w != nil && isImagePullFailure(w.Reason) {
+						before := rev.Status.GetCondition(v1alpha1.RevisionConditionContainerHealthy)
+						rev.Status.MarkImagePullFailed(w.Message)
+						if before == nil || before.Reason != "ImagePullFailed" {
+							c.Recorder.Eventf(rev, corev1.EventTypeWarning, "ImagePullFailed",
+								"Failed to pull image %q: %s", status.Image, w.Message)
+						}
+						// The kubelet keeps retrying the pull, but nothing about the
+						// Deployment changes when it finally succeeds, so check back.
+						c.enqueueAfter(rev, config.FromContext(ctx).Controller.ImagePullRetryPeriod)
+					} else if t := status.LastTerminationState.Terminated; t != nil {
 						rev.Status.MarkContainerExiting(t.ExitCode, t.Message)
 					}
 					break
@@ -87,6 +113,13 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1alpha1.Revi
 		}
 	}
 
+	// Once the Revision is ready its image is known to have been pulled, and
+	// checked, so there is no need to look it up on every reconcile.
+	if threshold := config.FromContext(ctx).Controller.ImageSizeThresholdBytes; threshold > 0 &&
+		deployment.Status.AvailableReplicas > 0 && !rev.Status.IsReady() {
+		c.checkImageSize(ctx, rev, deployment, threshold)
+	}
+
 	// Now that we have a Deployment, determine whether there is any relevant
 	// status to surface in the Revision.
 	if hasDeploymentTimedOut(deployment) && !rev.Status.IsActivationRequired() {
@@ -115,6 +148,73 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1alpha1.Revi
 	return nil
 }
 
+// checkImageSize surfaces whether the user container image, as reported by
+// the node that pulled it, is larger than the given threshold, and emits an
+// event when it newly is. Failing to determine the size is not an error, as
+// the check is only informational.
+func (c *Reconciler) checkImageSize(ctx context.Context, rev *v1alpha1.Revision, deployment *appsv1.Deployment, threshold int64) {
+	logger := logging.FromContext(ctx)
+
+	pods, err := c.KubeClientSet.CoreV1().Pods(rev.Namespace).List(metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(deployment.Spec.Selector)})
+	if err != nil {
+		logger.Errorf("Error getting pods: %v", err)
+		return
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != resources.UserContainerName || status.ImageID == "" {
+				continue
+			}
+			// All the pods run the same image, so one is enough.
+			node, err := c.nodeLister.Get(pod.Spec.NodeName)
+			if err != nil {
+				logger.Errorf("Error getting node %q: %v", pod.Spec.NodeName, err)
+				return
+			}
+			switch size := imageSize(node, status.ImageID); {
+			case size == 0:
+				// The node doesn't report the image (yet).
+			case size <= threshold:
+				rev.Status.MarkImageSizeWithinThreshold()
+			default:
+				before := rev.Status.GetCondition(v1alpha1.RevisionConditionImageSizeWithinThreshold)
+				rev.Status.MarkImageSizeExceeded(status.Image, size, threshold)
+				if before == nil || before.Status != corev1.ConditionFalse {
+					c.Recorder.Event(rev, corev1.EventTypeWarning, "ImageSizeExceeded",
+						v1alpha1.RevisionImageSizeExceededMessage(status.Image, size, threshold))
+				}
+			}
+			return
+		}
+	}
+}
+
+// imageSize returns the size of the image with the given container
+// status ImageID (e.g. docker-pullable://gcr.io/foo@sha256:...) on the
+// given node, or zero if the node does not report it.
+func imageSize(node *corev1.Node, imageID string) int64 {
+	if i := strings.Index(imageID, "://"); i >= 0 {
+		imageID = imageID[i+len("://"):]
+	}
+	for _, image := range node.Status.Images {
+		for _, name := range image.Names {
+			if name == imageID {
+				return image.SizeBytes
+			}
+		}
+	}
+	return 0
+}
+
+// isImagePullFailure returns whether the given container waiting reason
+// indicates that the kubelet is unable to pull the container's image.
+func isImagePullFailure(reason string) bool {
+	return reason == "ErrImagePull" || reason == "ImagePullBackOff"
+}
+
 func (c *Reconciler) reconcileKPA(ctx context.Context, rev *v1alpha1.Revision) error {
 	ns := rev.Namespace
 	kpaName := resourcenames.KPA(rev)
@@ -127,15 +227,25 @@ func (c *Reconciler) reconcileKPA(ctx context.Context, rev *v1alpha1.Revision) e
 		kpa, err = c.createKPA(ctx, rev)
 		if err != nil {
 			logger.Errorf("Error creating KPA %q: %v", kpaName, err)
+			c.Recorder.Eventf(rev, corev1.EventTypeWarning, "FailedCreatePodAutoscaler",
+				"Failed to create PodAutoscaler %q: %v", kpaName, err)
+			rev.Status.MarkResourceFailed("PodAutoscaler", kpaName, err.Error())
 			return err
 		}
 		logger.Infof("Created kpa %q", kpaName)
+		c.Recorder.Eventf(rev, corev1.EventTypeNormal, "CreatedPodAutoscaler",
+			"Created PodAutoscaler %q", kpaName)
 	} else if getKPAErr != nil {
 		logger.Errorf("Error reconciling kpa %q: %v", kpaName, getKPAErr)
 		return getKPAErr
+	} else if !metav1.IsControlledBy(kpa, rev) {
+		// Surface an error in the revision's status, and return an error.
+		rev.Status.MarkResourceNotOwned("PodAutoscaler", kpaName)
+		return fmt.Errorf("Revision: %q does not own PodAutoscaler: %q", rev.Name, kpaName)
 	}
 
 	// Reflect the KPA status in our own.
+	checkScaleAnnotations(rev)
 	cond := kpa.Status.GetCondition(kpav1alpha1.PodAutoscalerConditionReady)
 	switch {
 	case cond == nil:
@@ -150,6 +260,19 @@ func (c *Reconciler) reconcileKPA(ctx context.Context, rev *v1alpha1.Revision) e
 	return nil
 }
 
+// checkScaleAnnotations surfaces scale annotations the autoscaler cannot
+// parse. Admission rejects them, but older Revisions may still carry them.
+func checkScaleAnnotations(rev *v1alpha1.Revision) {
+	for _, key := range []string{autoscaling.MinScaleAnnotationKey, autoscaling.MaxScaleAnnotationKey} {
+		if v, ok := rev.Annotations[key]; ok {
+			if _, err := strconv.ParseInt(v, 10, 32); err != nil {
+				rev.Status.MarkInvalidScaleAnnotation(key, v)
+				return
+			}
+		}
+	}
+}
+
 func (c *Reconciler) reconcileService(ctx context.Context, rev *v1alpha1.Revision) error {
 	ns := rev.Namespace
 	serviceName := resourcenames.K8sService(rev)
@@ -162,24 +285,36 @@ func (c *Reconciler) reconcileService(ctx context.Context, rev *v1alpha1.Revisio
 	if apierrs.IsNotFound(err) {
 		// If it does not exist, then create it.
 		rev.Status.MarkDeploying("Deploying")
-		_, err = c.createService(ctx, rev, resources.MakeK8sService)
+		service, err = c.createService(ctx, rev, resources.MakeK8sService)
 		if err != nil {
 			logger.Errorf("Error creating Service %q: %v", serviceName, err)
+			c.Recorder.Eventf(rev, corev1.EventTypeWarning, "FailedCreateService",
+				"Failed to create Service %q: %v", serviceName, err)
+			rev.Status.MarkResourceFailed("Service", serviceName, err.Error())
 			return err
 		}
 		logger.Infof("Created Service %q", serviceName)
+		c.Recorder.Eventf(rev, corev1.EventTypeNormal, "CreatedService",
+			"Created Service %q", serviceName)
 	} else if err != nil {
 		logger.Errorf("Error reconciling Active Service %q: %v", serviceName, err)
 		return err
+	} else if !metav1.IsControlledBy(service, rev) {
+		// Surface an error in the revision's status, and return an error.
+		rev.Status.MarkResourceNotOwned("Service", serviceName)
+		return fmt.Errorf("Revision: %q does not own Service: %q", rev.Name, serviceName)
 	} else {
 		// If it exists, then make sure if looks as we expect.
 		// It may change if a user edits things around our controller, which we
 		// should not allow, or if our expectations of how the service should look
 		// changes (e.g. we update our controller with new sidecars).
 		var changed Changed
-		_, changed, err = c.checkAndUpdateService(ctx, rev, resources.MakeK8sService, service)
+		service, changed, err = c.checkAndUpdateService(ctx, rev, resources.MakeK8sService, service)
 		if err != nil {
 			logger.Errorf("Error updating Service %q: %v", serviceName, err)
+			c.Recorder.Eventf(rev, corev1.EventTypeWarning, "FailedUpdateService",
+				"Failed to update Service %q: %v", serviceName, err)
+			rev.Status.MarkResourceFailed("Service", serviceName, err.Error())
 			return err
 		}
 		if changed == WasChanged {
@@ -188,6 +323,10 @@ func (c *Reconciler) reconcileService(ctx context.Context, rev *v1alpha1.Revisio
 		}
 	}
 
+	// Surface the address the Service was assigned, so that consumers
+	// don't need to look it up themselves.
+	rev.Status.ClusterIP = service.Spec.ClusterIP
+
 	// We cannot determine readiness from the Service directly.  Instead, we look up
 	// the backing Endpoints resource and check it for healthy pods.  The name of the
 	// Endpoints resource matches the Service it backs.
@@ -206,22 +345,26 @@ func (c *Reconciler) reconcileService(ctx context.Context, rev *v1alpha1.Revisio
 	// If the endpoints resource indicates that the Service it sits in front of is ready,
 	// then surface this in our Revision status as resources available (pods were scheduled)
 	// and container healthy (endpoints should be gated by any provided readiness checks).
-	if getIsServiceReady(endpoints) {
+	if getIsServiceReady(endpoints) && c.hasAvailablePods(ctx, rev) {
+		wasReady := rev.Status.IsReady()
 		rev.Status.MarkResourcesAvailable()
 		rev.Status.MarkContainerHealthy()
-		// TODO(mattmoor): How to ensure this only fires once?
-		c.Recorder.Eventf(rev, corev1.EventTypeNormal, "RevisionReady",
-			"Revision becomes ready upon endpoint %q becoming ready", serviceName)
+		if !wasReady && rev.Status.IsReady() {
+			c.Recorder.Eventf(rev, corev1.EventTypeNormal, "RevisionReady",
+				"Revision becomes ready upon endpoint %q becoming ready", serviceName)
+		}
 	} else if !rev.Status.IsActivationRequired() {
 		// If the endpoints is NOT ready, then check whether it is taking unreasonably
 		// long to become ready and if so mark our revision as having timed out waiting
 		// for the Service to become ready.
 		revisionAge := time.Now().Sub(getRevisionLastTransitionTime(rev))
 		if revisionAge >= serviceTimeoutDuration {
+			before := rev.Status.GetCondition(v1alpha1.RevisionConditionResourcesAvailable)
 			rev.Status.MarkServiceTimeout()
-			// TODO(mattmoor): How to ensure this only fires once?
-			c.Recorder.Eventf(rev, corev1.EventTypeWarning, "RevisionFailed",
-				"Revision did not become ready due to endpoint %q", serviceName)
+			if before == nil || before.Reason != "ServiceTimeout" {
+				c.Recorder.Eventf(rev, corev1.EventTypeWarning, "RevisionFailed",
+					"Revision did not become ready due to endpoint %q", serviceName)
+			}
 		}
 	}
 	return nil
@@ -245,6 +388,7 @@ func (c *Reconciler) reconcileFluentdConfigMap(ctx context.Context, rev *v1alpha
 		configMap, err = c.KubeClientSet.CoreV1().ConfigMaps(ns).Create(desiredConfigMap)
 		if err != nil {
 			logger.Error("Error creating fluentd configmap", zap.Error(err))
+			rev.Status.MarkResourceFailed("ConfigMap", name, err.Error())
 			return err
 		}
 		logger.Infof("Created fluentd configmap: %q", name)
@@ -266,9 +410,21 @@ func (c *Reconciler) reconcileFluentdConfigMap(ctx context.Context, rev *v1alpha
 			_, err = c.KubeClientSet.CoreV1().ConfigMaps(ns).Update(existing)
 			if err != nil {
 				logger.Error("Error updating fluentd configmap", zap.Error(err))
+				rev.Status.MarkResourceFailed("ConfigMap", name, err.Error())
 				return err
 			}
 		}
 	}
 	return nil
 }
+
+// hasAvailablePods returns whether a pod of the Revision has been ready for
+// the configured minReadySeconds, as its Deployment reports. Endpoints list
+// pods as soon as they are ready.
+func (c *Reconciler) hasAvailablePods(ctx context.Context, rev *v1alpha1.Revision) bool {
+	if config.FromContext(ctx).Controller.MinReadySeconds == 0 {
+		return true
+	}
+	deployment, err := c.deploymentLister.Deployments(rev.Namespace).Get(resourcenames.Deployment(rev))
+	return err == nil && deployment.Status.AvailableReplicas > 0
+}
@@ -23,23 +23,43 @@ import (
 const (
 	// UserContainerName is the name of the user-container in the PodSpec
 	UserContainerName = "user-container"
+	// UserSidecarNamePrefix prefixes the names of the user's other containers
+	// in the PodSpec, which are suffixed with their index
+	UserSidecarNamePrefix = "user-sidecar-"
 	// FluentdContainerName is the name of the fluentd sidecar when enabled
 	FluentdContainerName = "fluentd-proxy"
 	// EnvoyContainerName is the name of the envoy sidecar when enabled
 	EnvoyContainerName = "istio-proxy"
 	// QueueContainerName is the name of the queue proxy side car
 	QueueContainerName = "queue-proxy"
+	// DebugContainerName is the name of the debug sidecar when requested
+	DebugContainerName = "debug-sidecar"
+	// ModelLoaderContainerName is the name of the model loader init
+	// container when requested
+	ModelLoaderContainerName = "model-loader"
 
 	sidecarIstioInjectAnnotation = "sidecar.istio.io/inject"
 	// TODO(mattmoor): Make this private once we remove revision_test.go
 	IstioOutboundIPRangeAnnotation = "traffic.sidecar.istio.io/includeOutboundIPRanges"
 
+	// The annotations Prometheus discovers its scrape targets from.
+	prometheusScrapeAnnotation = "prometheus.io/scrape"
+	prometheusPortAnnotation   = "prometheus.io/port"
+	prometheusPathAnnotation   = "prometheus.io/path"
+
+	// configChecksumAnnotation holds a checksum of the config mounted into
+	// the pods, so that changing it rolls the Deployment.
+	configChecksumAnnotation = "serving.knative.dev/configChecksum"
+
 	userPortEnvName = "PORT"
 
 	autoscalerPort = 8080
 
 	// ServicePortName is the name of the external port of the service
 	ServicePortName = "http"
+	// ServicePortNameH2C is the name of the external port of the service
+	// of Revisions speaking h2c, which tells Istio to proxy HTTP/2 to it.
+	ServicePortNameH2C = "http2"
 	// ServicePort is the external port of the service
 	ServicePort = int32(80)
 	// MetricsPortName is the name of the external port of the service for metrics
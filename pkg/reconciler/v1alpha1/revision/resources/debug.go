@@ -0,0 +1,51 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/reconciler/v1alpha1/revision/config"
+)
+
+// wantsDebugSidecar returns whether the debug sidecar should be injected into
+// the Revision's pods. Revisions opt in through an annotation, but nothing is
+// injected unless the operator has configured a debug sidecar image.
+func wantsDebugSidecar(rev *v1alpha1.Revision, controllerConfig *config.Controller) bool {
+	if controllerConfig.DebugSidecarImage == "" {
+		return false
+	}
+	b, _ := strconv.ParseBool(rev.Annotations[serving.DebugSidecarAnnotationKey])
+	return b
+}
+
+func makeDebugContainer(controllerConfig *config.Controller) *corev1.Container {
+	return &corev1.Container{
+		Name:  DebugContainerName,
+		Image: controllerConfig.DebugSidecarImage,
+		// Keep the container running so operators can exec or attach into it.
+		Stdin: true,
+		TTY:   true,
+		VolumeMounts: []corev1.VolumeMount{
+			varLogVolumeMount,
+		},
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knative/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/reconciler/v1alpha1/revision/config"
+)
+
+func TestMakePodSpecDebugSidecar(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		cc          *config.Controller
+		want        []string
+	}{{
+		name: "requested and enabled",
+		annotations: map[string]string{
+			serving.DebugSidecarAnnotationKey: "true",
+		},
+		cc:   &config.Controller{DebugSidecarImage: "busybox"},
+		want: []string{UserContainerName, QueueContainerName, DebugContainerName},
+	}, {
+		name: "requested but disabled by the operator",
+		annotations: map[string]string{
+			serving.DebugSidecarAnnotationKey: "true",
+		},
+		cc:   &config.Controller{},
+		want: []string{UserContainerName, QueueContainerName},
+	}, {
+		name:        "annotation cleared",
+		annotations: map[string]string{},
+		cc:          &config.Controller{DebugSidecarImage: "busybox"},
+		want:        []string{UserContainerName, QueueContainerName},
+	}, {
+		name: "annotation set to false",
+		annotations: map[string]string{
+			serving.DebugSidecarAnnotationKey: "false",
+		},
+		cc:   &config.Controller{DebugSidecarImage: "busybox"},
+		want: []string{UserContainerName, QueueContainerName},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "foo",
+					Name:        "bar",
+					UID:         "1234",
+					Annotations: test.annotations,
+				},
+				Spec: v1alpha1.RevisionSpec{
+					Container: corev1.Container{
+						Image: "busybox",
+					},
+				},
+			}
+			podSpec := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, test.cc)
+
+			var got []string
+			for _, c := range podSpec.Containers {
+				got = append(got, c.Name)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("makePodSpec containers (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakeDebugContainer(t *testing.T) {
+	want := &corev1.Container{
+		Name:  DebugContainerName,
+		Image: "busybox",
+		Stdin: true,
+		TTY:   true,
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      varLogVolumeName,
+			MountPath: "/var/log",
+		}},
+	}
+	got := makeDebugContainer(&config.Controller{DebugSidecarImage: "busybox"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("makeDebugContainer (-want, +got) = %v", diff)
+	}
+}
@@ -17,10 +17,15 @@ limitations under the License.
 package resources
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/knative/pkg/kmeta"
 	"github.com/knative/pkg/logging"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
 	"github.com/knative/serving/pkg/queue"
@@ -53,22 +58,39 @@ var (
 			corev1.ResourceCPU: userContainerCPU,
 		},
 	}
+)
+
+// configChecksum returns a checksum of the given ConfigMap data.
+func configChecksum(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", k, data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
 
+// makeUserLifecycle returns the lifecycle of the user container.
+func makeUserLifecycle(networkConfig *config.Network) *corev1.Lifecycle {
 	// This PreStop hook is actually calling an endpoint on the queue-proxy
 	// because of the way PreStop hooks are called by kubelet. We use this
 	// to block the user-container from exiting before the queue-proxy is ready
 	// to exit so we can guarantee that there are no more requests in flight.
-	userLifecycle = &corev1.Lifecycle{
+	return &corev1.Lifecycle{
 		PreStop: &corev1.Handler{
 			HTTPGet: &corev1.HTTPGetAction{
-				Port: intstr.FromInt(v1alpha1.RequestQueueAdminPort),
+				Port: intstr.FromInt(networkConfig.QueueProxyPorts.Admin),
 				Path: queue.RequestQueueQuitPath,
 			},
 		},
 	}
-)
+}
 
-func rewriteUserProbe(p *corev1.Probe, userPort int) {
+func rewriteUserProbe(p *corev1.Probe, userPort int, networkConfig *config.Network) {
 	if p == nil {
 		return
 	}
@@ -76,12 +98,24 @@ func rewriteUserProbe(p *corev1.Probe, userPort int) {
 	case p.HTTPGet != nil:
 		// For HTTP probes, we route them through the queue container
 		// so that we know the queue proxy is ready/live as well.
-		p.HTTPGet.Port = intstr.FromInt(v1alpha1.RequestQueuePort)
+		p.HTTPGet.Port = intstr.FromInt(networkConfig.QueueProxyPorts.Serving)
 	case p.TCPSocket != nil:
 		p.TCPSocket.Port = intstr.FromInt(userPort)
 	}
 }
 
+// applyReadinessProbeTimeout makes the readiness probe of Revisions that
+// only handle one request at a time more lenient, as the probe may have to
+// wait for the in-flight request to complete.
+func applyReadinessProbeTimeout(p *corev1.Probe, rev *v1alpha1.Revision, controllerConfig *config.Controller) {
+	if p == nil || p.TimeoutSeconds != 0 {
+		return
+	}
+	if rev.Spec.ContainerConcurrency == 1 {
+		p.TimeoutSeconds = controllerConfig.SingleConcurrencyProbeTimeoutSeconds
+	}
+}
+
 // applyDefaultResource
 // Implements a deep merge for ResourceRequirements
 // note: DeepCopyInto cannot be used because it replaces limits or requests instead of merging them
@@ -103,24 +137,67 @@ func applyDefaultResources(defaults corev1.ResourceRequirements, out *corev1.Res
 	}
 }
 
-func makePodSpec(rev *v1alpha1.Revision, loggingConfig *logging.Config, observabilityConfig *config.Observability, autoscalerConfig *autoscaler.Config, controllerConfig *config.Controller) *corev1.PodSpec {
-	userContainer := rev.Spec.Container.DeepCopy()
+// applyDefaultMemoryLimit gives the user container the configured memory
+// limit when it has none. A container requesting more memory than that keeps
+// no limit, as a limit below the request would make its pods invalid.
+func applyDefaultMemoryLimit(out *corev1.ResourceRequirements, controllerConfig *config.Controller) {
+	limit := controllerConfig.UserContainerMemoryLimit
+	if limit == nil {
+		return
+	}
+	if _, ok := out.Limits[corev1.ResourceMemory]; ok {
+		return
+	}
+	if request, ok := out.Requests[corev1.ResourceMemory]; ok && request.Cmp(*limit) > 0 {
+		return
+	}
+	if out.Limits == nil {
+		out.Limits = corev1.ResourceList{}
+	}
+	out.Limits[corev1.ResourceMemory] = limit.DeepCopy()
+}
+
+// applyDefaultEphemeralStorageRequest gives the user container the configured
+// ephemeral-storage request when it has none. A container whose limit is
+// below that keeps no request, which Kubernetes then defaults to the limit.
+func applyDefaultEphemeralStorageRequest(out *corev1.ResourceRequirements, controllerConfig *config.Controller) {
+	request := controllerConfig.UserContainerEphemeralStorageRequest
+	if request == nil {
+		return
+	}
+	if _, ok := out.Requests[corev1.ResourceEphemeralStorage]; ok {
+		return
+	}
+	if limit, ok := out.Limits[corev1.ResourceEphemeralStorage]; ok && limit.Cmp(*request) < 0 {
+		return
+	}
+	if out.Requests == nil {
+		out.Requests = corev1.ResourceList{}
+	}
+	out.Requests[corev1.ResourceEphemeralStorage] = request.DeepCopy()
+}
+
+func makePodSpec(rev *v1alpha1.Revision, loggingConfig *logging.Config, networkConfig *config.Network, observabilityConfig *config.Observability, autoscalerConfig *autoscaler.Config, controllerConfig *config.Controller) *corev1.PodSpec {
+	userContainer := rev.Spec.ServingContainer().DeepCopy()
 	// Adding or removing an overwritten corev1.Container field here? Don't forget to
 	// update the validations in pkg/webhook.validateContainer.
 	userContainer.Name = UserContainerName
 
 	// If client provides for some resources, override default values
 	applyDefaultResources(userResources, &userContainer.Resources)
+	applyDefaultMemoryLimit(&userContainer.Resources, controllerConfig)
+	applyDefaultEphemeralStorageRequest(&userContainer.Resources, controllerConfig)
 
 	userContainer.VolumeMounts = append(userContainer.VolumeMounts, varLogVolumeMount)
-	userContainer.Lifecycle = userLifecycle
+	userContainer.Lifecycle = makeUserLifecycle(networkConfig)
 	userPort := getUserPort(rev)
 	userPortInt := int(userPort)
 	userPortStr := strconv.Itoa(userPortInt)
-	// Replacement is safe as only up to a single port is allowed on the Revision
-	userContainer.Ports = buildContainerPorts(userPort)
+	// The serving port is renamed, additional ports are exposed as they are.
+	userContainer.Ports = append(buildContainerPorts(userPort), getAdditionalPorts(rev)...)
 	userContainer.Env = append(userContainer.Env, buildUserPortEnv(userPortStr))
 	userContainer.Env = append(userContainer.Env, getKnativeEnvVar(rev)...)
+	applyPodSecurityContext(rev.Spec.SecurityContext, userContainer)
 
 	// Prefer imageDigest from revision if available
 	if rev.Status.ImageDigest != "" {
@@ -131,34 +208,125 @@ func makePodSpec(rev *v1alpha1.Revision, loggingConfig *logging.Config, observab
 		userContainer.TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
 	}
 
+	if userContainer.ReadinessProbe == nil && controllerConfig.DefaultReadinessProbe {
+		userContainer.ReadinessProbe = &corev1.Probe{
+			Handler: corev1.Handler{
+				TCPSocket: &corev1.TCPSocketAction{},
+			},
+		}
+	}
+
 	// If the client provides probes, we should fill in the port for them.
-	rewriteUserProbe(userContainer.ReadinessProbe, userPortInt)
-	rewriteUserProbe(userContainer.LivenessProbe, userPortInt)
+	rewriteUserProbe(userContainer.ReadinessProbe, userPortInt, networkConfig)
+	rewriteUserProbe(userContainer.LivenessProbe, userPortInt, networkConfig)
+	applyReadinessProbeTimeout(userContainer.ReadinessProbe, rev, controllerConfig)
+
+	if _, ok := modelSource(rev, controllerConfig); ok {
+		userContainer.VolumeMounts = append(userContainer.VolumeMounts, modelVolumeMount)
+		userContainer.Env = append(userContainer.Env, modelDirEnv)
+	}
 
 	revisionTimeout := rev.Spec.TimeoutSeconds
 
 	podSpec := &corev1.PodSpec{
 		Containers: []corev1.Container{
 			*userContainer,
-			*makeQueueContainer(rev, loggingConfig, autoscalerConfig, controllerConfig),
+			*makeQueueContainer(rev, loggingConfig, networkConfig, autoscalerConfig, controllerConfig),
 		},
-		Volumes:                       []corev1.Volume{varLogVolume},
+		Volumes:                       append([]corev1.Volume{varLogVolume}, rev.Spec.Volumes...),
 		ServiceAccountName:            rev.Spec.ServiceAccountName,
+		ImagePullSecrets:              rev.Spec.ImagePullSecrets,
 		TerminationGracePeriodSeconds: &revisionTimeout,
+		SecurityContext:               makePodSecurityContext(rev.Spec.SecurityContext),
+		NodeSelector:                  rev.Spec.NodeSelector,
+		Tolerations:                   rev.Spec.Tolerations,
+		// Pods are managed by a Deployment, which only supports Always.
+		// Validation rejects any other policy requested for serving Revisions.
+		RestartPolicy: corev1.RestartPolicyAlways,
 	}
 
+	// Run the user's other containers next to the ones we inject.
+	podSpec.Containers = append(podSpec.Containers, makeUserSidecars(rev)...)
+
 	// Add Fluentd sidecar and its config map volume if var log collection is enabled.
 	if observabilityConfig.EnableVarLogCollection {
 		podSpec.Containers = append(podSpec.Containers, *makeFluentdContainer(rev, observabilityConfig))
 		podSpec.Volumes = append(podSpec.Volumes, *makeFluentdConfigMapVolume(rev))
 	}
 
+	// Load the model into a volume shared with the user container before it
+	// starts, if the Revision asks for it and the operator allows it.
+	if source, ok := modelSource(rev, controllerConfig); ok {
+		podSpec.InitContainers = append(podSpec.InitContainers, *makeModelLoaderContainer(source, controllerConfig))
+		podSpec.Volumes = append(podSpec.Volumes, modelVolume)
+	}
+
+	// Add the debug sidecar if the Revision asks for it and the operator allows it.
+	if wantsDebugSidecar(rev, controllerConfig) {
+		podSpec.Containers = append(podSpec.Containers, *makeDebugContainer(controllerConfig))
+	}
+
 	return podSpec
 }
 
+// makeUserSidecars returns the user containers of the Revision other than the
+// serving one. Their logs go to the same volume as the user container's.
+func makeUserSidecars(rev *v1alpha1.Revision) []corev1.Container {
+	var sidecars []corev1.Container
+	for i, c := range rev.Spec.Sidecars() {
+		sidecar := c.DeepCopy()
+		sidecar.Name = UserSidecarNamePrefix + strconv.Itoa(i)
+		sidecar.VolumeMounts = append(sidecar.VolumeMounts, varLogVolumeMount)
+		applyPodSecurityContext(rev.Spec.SecurityContext, sidecar)
+		if sidecar.TerminationMessagePolicy == "" {
+			sidecar.TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
+		}
+		sidecars = append(sidecars, *sidecar)
+	}
+	return sidecars
+}
+
+// applyPodSecurityContext sets the pod-level security attributes of the
+// Revision that are about processes on one of its containers, unless the
+// container overrides them. Setting them on the pod would also apply them
+// to the queue-proxy and the other containers we inject.
+func applyPodSecurityContext(psc *corev1.PodSecurityContext, container *corev1.Container) {
+	if psc == nil {
+		return
+	}
+	if container.SecurityContext == nil {
+		container.SecurityContext = &corev1.SecurityContext{}
+	}
+	sc := container.SecurityContext
+	if sc.SELinuxOptions == nil {
+		sc.SELinuxOptions = psc.SELinuxOptions
+	}
+	if sc.RunAsUser == nil {
+		sc.RunAsUser = psc.RunAsUser
+	}
+	if sc.RunAsGroup == nil {
+		sc.RunAsGroup = psc.RunAsGroup
+	}
+	if sc.RunAsNonRoot == nil {
+		sc.RunAsNonRoot = psc.RunAsNonRoot
+	}
+}
+
+// makePodSecurityContext returns the pod-level security attributes of the
+// Revision that are about its volumes, which only exist on the pod.
+func makePodSecurityContext(psc *corev1.PodSecurityContext) *corev1.PodSecurityContext {
+	if psc == nil || (psc.FSGroup == nil && len(psc.SupplementalGroups) == 0) {
+		return nil
+	}
+	return &corev1.PodSecurityContext{
+		FSGroup:            psc.FSGroup,
+		SupplementalGroups: psc.SupplementalGroups,
+	}
+}
+
 func getUserPort(rev *v1alpha1.Revision) int32 {
-	if len(rev.Spec.Container.Ports) == 1 {
-		return rev.Spec.Container.Ports[0].ContainerPort
+	if p := v1alpha1.ServingPort(*rev.Spec.ServingContainer()); p != nil {
+		return p.ContainerPort
 	}
 
 	//TODO(#2258): Use container EXPOSE metadata from image before falling back to default value
@@ -173,6 +341,19 @@ func buildContainerPorts(userPort int32) []corev1.ContainerPort {
 	}}
 }
 
+// getAdditionalPorts returns the ports of the Revision other than the serving port.
+func getAdditionalPorts(rev *v1alpha1.Revision) []corev1.ContainerPort {
+	container := rev.Spec.ServingContainer()
+	serving := v1alpha1.ServingPort(*container)
+	var additional []corev1.ContainerPort
+	for i, p := range container.Ports {
+		if &container.Ports[i] != serving {
+			additional = append(additional, p)
+		}
+	}
+	return additional
+}
+
 func buildUserPortEnv(userPort string) corev1.EnvVar {
 	return corev1.EnvVar{
 		Name:  userPortEnvName,
@@ -180,6 +361,18 @@ func buildUserPortEnv(userPort string) corev1.EnvVar {
 	}
 }
 
+// initialReplicas returns the number of replicas a new Deployment for the
+// given Revision starts with: its minimum scale, if it has one, or one. The
+// autoscaler owns the replica count once the Deployment exists.
+func initialReplicas(rev *v1alpha1.Revision) int32 {
+	if s, ok := rev.Annotations[autoscaling.MinScaleAnnotationKey]; ok {
+		if min, err := strconv.ParseInt(s, 10, 32); err == nil && min > 1 {
+			return int32(min)
+		}
+	}
+	return 1
+}
+
 func MakeDeployment(rev *v1alpha1.Revision,
 	loggingConfig *logging.Config, networkConfig *config.Network, observabilityConfig *config.Observability,
 	autoscalerConfig *autoscaler.Config, controllerConfig *config.Controller) *appsv1.Deployment {
@@ -205,7 +398,23 @@ func MakeDeployment(rev *v1alpha1.Revision,
 		}
 	}
 
-	one := int32(1)
+	// Point annotation-based Prometheus discovery at the queue-proxy metrics,
+	// unless the user has annotated the pods themselves.
+	if observabilityConfig.EnablePrometheusScrapeAnnotations {
+		if _, ok := podTemplateAnnotations[prometheusScrapeAnnotation]; !ok {
+			podTemplateAnnotations[prometheusScrapeAnnotation] = "true"
+			podTemplateAnnotations[prometheusPortAnnotation] = strconv.Itoa(networkConfig.QueueProxyPorts.Metrics)
+			podTemplateAnnotations[prometheusPathAnnotation] = "/metrics"
+		}
+	}
+
+	// The fluentd sidecar only reads its config on startup.
+	if observabilityConfig.EnableVarLogCollection {
+		podTemplateAnnotations[configChecksumAnnotation] = configChecksum(
+			MakeFluentdConfigMap(rev, observabilityConfig).Data)
+	}
+
+	replicas := initialReplicas(rev)
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            names.Deployment(rev),
@@ -215,15 +424,16 @@ func MakeDeployment(rev *v1alpha1.Revision,
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(rev)},
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas:                &one,
+			Replicas:                &replicas,
 			Selector:                makeSelector(rev),
 			ProgressDeadlineSeconds: &ProgressDeadlineSeconds,
+			MinReadySeconds:         controllerConfig.MinReadySeconds,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      makeLabels(rev),
 					Annotations: podTemplateAnnotations,
 				},
-				Spec: *makePodSpec(rev, loggingConfig, observabilityConfig, autoscalerConfig, controllerConfig),
+				Spec: *makePodSpec(rev, loggingConfig, networkConfig, observabilityConfig, autoscalerConfig, controllerConfig),
 			},
 		},
 	}
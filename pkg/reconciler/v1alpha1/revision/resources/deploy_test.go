@@ -18,11 +18,14 @@ package resources
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/knative/pkg/logging"
+	"github.com/knative/serving/pkg/apis/autoscaling"
+	apisconfig "github.com/knative/serving/pkg/apis/config"
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
@@ -37,6 +40,14 @@ import (
 var (
 	one            int32  = 1
 	defaultPortStr string = strconv.Itoa(int(v1alpha1.DefaultUserPort))
+
+	defaultNetworkConfig = &config.Network{
+		QueueProxyPorts: apisconfig.QueueProxyPorts{
+			Serving: v1alpha1.RequestQueuePort,
+			Admin:   v1alpha1.RequestQueueAdminPort,
+			Metrics: v1alpha1.RequestQueueMetricsPort,
+		},
+	}
 )
 
 func refInt64(num int64) *int64 {
@@ -91,7 +102,7 @@ func TestMakePodSpec(t *testing.T) {
 					},
 				},
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{{
 					Name:  "PORT",
@@ -109,9 +120,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
 					Value: "foo", // matches namespace
@@ -147,10 +158,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8888", // Match user port
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "simple concurrency=single no owner",
@@ -180,7 +201,7 @@ func TestMakePodSpec(t *testing.T) {
 				Resources:                userResources,
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{buildUserPortEnv(defaultPortStr),
 					{
@@ -196,9 +217,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -235,10 +256,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "simple concurrency=single no owner digest resolved",
@@ -271,7 +302,7 @@ func TestMakePodSpec(t *testing.T) {
 				Resources:                userResources,
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{buildUserPortEnv(defaultPortStr),
 					{
@@ -287,9 +318,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -326,10 +357,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "simple concurrency=single with owner",
@@ -366,7 +407,7 @@ func TestMakePodSpec(t *testing.T) {
 				Resources:                userResources,
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{buildUserPortEnv(defaultPortStr),
 					{
@@ -382,9 +423,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -421,10 +462,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "simple concurrency=multi http readiness probe",
@@ -470,7 +521,7 @@ func TestMakePodSpec(t *testing.T) {
 				Resources:                userResources,
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{buildUserPortEnv(defaultPortStr),
 					{
@@ -486,9 +537,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -525,10 +576,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "concurrency=multi, readinessprobe=shell",
@@ -572,7 +633,7 @@ func TestMakePodSpec(t *testing.T) {
 				Resources:                userResources,
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{buildUserPortEnv(defaultPortStr),
 					{
@@ -588,9 +649,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -627,10 +688,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "concurrency=multi, readinessprobe=http",
@@ -676,7 +747,7 @@ func TestMakePodSpec(t *testing.T) {
 				Resources:                userResources,
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{buildUserPortEnv(defaultPortStr),
 					{
@@ -692,9 +763,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -731,10 +802,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "concurrency=multi, livenessprobe=tcp",
@@ -776,7 +857,7 @@ func TestMakePodSpec(t *testing.T) {
 				Resources:                userResources,
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{buildUserPortEnv(defaultPortStr),
 					{
@@ -792,9 +873,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -831,10 +912,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "with /var/log collection",
@@ -867,7 +958,7 @@ func TestMakePodSpec(t *testing.T) {
 				Resources:                userResources,
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Env: []corev1.EnvVar{buildUserPortEnv(defaultPortStr),
 					{
@@ -883,9 +974,9 @@ func TestMakePodSpec(t *testing.T) {
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -922,6 +1013,15 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}, {
 				Name:      FluentdContainerName,
@@ -963,6 +1063,7 @@ func TestMakePodSpec(t *testing.T) {
 				},
 			}},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}, {
 		name: "complex pod spec",
@@ -1041,14 +1142,14 @@ func TestMakePodSpec(t *testing.T) {
 				},
 				Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
 				VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
-				Lifecycle:                userLifecycle,
+				Lifecycle:                makeUserLifecycle(defaultNetworkConfig),
 				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
 			}, {
 				Name:           QueueContainerName,
 				Resources:      queueResources,
-				Ports:          queuePorts,
-				Lifecycle:      queueLifecycle,
-				ReadinessProbe: queueReadinessProbe,
+				Ports:          makeQueuePorts(defaultNetworkConfig),
+				Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+				ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 				// These changed based on the Revision and configs passed in.
 				Env: []corev1.EnvVar{{
 					Name:  "SERVING_NAMESPACE",
@@ -1085,10 +1186,20 @@ func TestMakePodSpec(t *testing.T) {
 				}, {
 					Name:  "USER_PORT",
 					Value: "8080",
+				}, {
+					Name:  "QUEUE_SERVING_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+				}, {
+					Name:  "QUEUE_ADMIN_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+				}, {
+					Name:  "QUEUE_METRICS_PORT",
+					Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 				}},
 			}},
 			Volumes:                       []corev1.Volume{varLogVolume},
 			TerminationGracePeriodSeconds: refInt64(45),
+			RestartPolicy:                 corev1.RestartPolicyAlways,
 		},
 	}}
 
@@ -1098,7 +1209,7 @@ func TestMakePodSpec(t *testing.T) {
 				return x.Cmp(y) == 0
 			})
 
-			got := makePodSpec(test.rev, test.lc, test.oc, test.ac, test.cc)
+			got := makePodSpec(test.rev, test.lc, defaultNetworkConfig, test.oc, test.ac, test.cc)
 			if diff := cmp.Diff(test.want, got, quantityComparer); diff != "" {
 				t.Errorf("makePodSpec (-want, +got) = %v", diff)
 			}
@@ -1133,7 +1244,7 @@ func TestMakeDeployment(t *testing.T) {
 			},
 		},
 		lc: &logging.Config{},
-		nc: &config.Network{},
+		nc: defaultNetworkConfig,
 		oc: &config.Observability{},
 		ac: &autoscaler.Config{},
 		cc: &config.Controller{},
@@ -1203,7 +1314,7 @@ func TestMakeDeployment(t *testing.T) {
 			},
 		},
 		lc: &logging.Config{},
-		nc: &config.Network{},
+		nc: defaultNetworkConfig,
 		oc: &config.Observability{},
 		ac: &autoscaler.Config{},
 		cc: &config.Controller{},
@@ -1212,9 +1323,10 @@ func TestMakeDeployment(t *testing.T) {
 				Namespace: "foo",
 				Name:      "bar-deployment",
 				Labels: map[string]string{
-					serving.RevisionLabelKey: "bar",
-					serving.RevisionUID:      "1234",
-					AppLabelKey:              "bar",
+					serving.RevisionLabelKey:      "bar",
+					serving.RevisionUID:           "1234",
+					serving.ConfigurationLabelKey: "parent-config",
+					AppLabelKey:                   "bar",
 				},
 				Annotations: map[string]string{},
 				OwnerReferences: []metav1.OwnerReference{{
@@ -1237,9 +1349,10 @@ func TestMakeDeployment(t *testing.T) {
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
 						Labels: map[string]string{
-							serving.RevisionLabelKey: "bar",
-							serving.RevisionUID:      "1234",
-							AppLabelKey:              "bar",
+							serving.RevisionLabelKey:      "bar",
+							serving.RevisionUID:           "1234",
+							serving.ConfigurationLabelKey: "parent-config",
+							AppLabelKey:                   "bar",
 						},
 						Annotations: map[string]string{
 							sidecarIstioInjectAnnotation: "true",
@@ -1268,6 +1381,7 @@ func TestMakeDeployment(t *testing.T) {
 		lc: &logging.Config{},
 		nc: &config.Network{
 			IstioOutboundIPRanges: "*",
+			QueueProxyPorts:       defaultNetworkConfig.QueueProxyPorts,
 		},
 		oc: &config.Observability{},
 		ac: &autoscaler.Config{},
@@ -1337,6 +1451,7 @@ func TestMakeDeployment(t *testing.T) {
 		lc: &logging.Config{},
 		nc: &config.Network{
 			IstioOutboundIPRanges: "*",
+			QueueProxyPorts:       defaultNetworkConfig.QueueProxyPorts,
 		},
 		oc: &config.Observability{},
 		ac: &autoscaler.Config{},
@@ -1392,7 +1507,7 @@ func TestMakeDeployment(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			// Tested above so that we can rely on it here for brevity.
-			test.want.Spec.Template.Spec = *makePodSpec(test.rev, test.lc, test.oc, test.ac, test.cc)
+			test.want.Spec.Template.Spec = *makePodSpec(test.rev, test.lc, test.nc, test.oc, test.ac, test.cc)
 			got := MakeDeployment(test.rev, test.lc, test.nc, test.oc, test.ac, test.cc)
 			if diff := cmp.Diff(test.want, got, cmpopts.IgnoreUnexported(resource.Quantity{})); diff != "" {
 				t.Errorf("MakeDeployment (-want, +got) = %v", diff)
@@ -1483,3 +1598,746 @@ func TestApplyDefaultResources(t *testing.T) {
 		})
 	}
 }
+
+func TestMakePodSpecReadinessProbeTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		cc      v1alpha1.RevisionContainerConcurrencyType
+		timeout int32
+		want    int32
+	}{{
+		name: "single concurrency gets the configured timeout",
+		cc:   1,
+		want: 10,
+	}, {
+		name: "multi concurrency keeps the default timeout",
+		cc:   0,
+		want: 0,
+	}, {
+		name: "bounded multi concurrency keeps the default timeout",
+		cc:   5,
+		want: 0,
+	}, {
+		name:    "user-specified timeout is preserved",
+		cc:      1,
+		timeout: 3,
+		want:    3,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: v1alpha1.RevisionSpec{
+					ContainerConcurrency: test.cc,
+					Container: corev1.Container{
+						Image: "busybox",
+						ReadinessProbe: &corev1.Probe{
+							TimeoutSeconds: test.timeout,
+							Handler: corev1.Handler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path: "/",
+								},
+							},
+						},
+					},
+				},
+			}
+			cc := &config.Controller{SingleConcurrencyProbeTimeoutSeconds: 10}
+			podSpec := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, cc)
+			if got := podSpec.Containers[0].ReadinessProbe.TimeoutSeconds; got != test.want {
+				t.Errorf("ReadinessProbe.TimeoutSeconds = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMakePodSpecMemoryLimit(t *testing.T) {
+	defaultLimit := resource.MustParse("1Gi")
+	tests := []struct {
+		name      string
+		resources corev1.ResourceRequirements
+		limit     *resource.Quantity
+		want      corev1.ResourceList
+	}{{
+		name: "no configured default",
+		want: nil,
+	}, {
+		name:  "configured default is applied",
+		limit: &defaultLimit,
+		want: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}, {
+		name: "user limits are kept",
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+				corev1.ResourceCPU:    resource.MustParse("1"),
+			},
+		},
+		limit: &defaultLimit,
+		want: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+			corev1.ResourceCPU:    resource.MustParse("1"),
+		},
+	}, {
+		name: "user cpu limit gets the default memory limit",
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+		},
+		limit: &defaultLimit,
+		want: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+			corev1.ResourceCPU:    resource.MustParse("1"),
+		},
+	}, {
+		name: "memory request above the default",
+		resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+		limit: &defaultLimit,
+		want:  nil,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: v1alpha1.RevisionSpec{
+					Container: corev1.Container{
+						Image:     "busybox",
+						Resources: test.resources,
+					},
+				},
+			}
+			quantityComparer := cmp.Comparer(func(x, y resource.Quantity) bool {
+				return x.Cmp(y) == 0
+			})
+
+			cc := &config.Controller{UserContainerMemoryLimit: test.limit}
+			podSpec := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, cc)
+			if diff := cmp.Diff(test.want, podSpec.Containers[0].Resources.Limits, quantityComparer); diff != "" {
+				t.Errorf("Resources.Limits (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakePodSpecEphemeralStorageRequest(t *testing.T) {
+	defaultRequest := resource.MustParse("50Mi")
+	tests := []struct {
+		name      string
+		resources corev1.ResourceRequirements
+		request   *resource.Quantity
+		want      corev1.ResourceList
+	}{{
+		name: "no configured default",
+		want: corev1.ResourceList{
+			corev1.ResourceCPU: userContainerCPU,
+		},
+	}, {
+		name:    "configured default is applied",
+		request: &defaultRequest,
+		want: corev1.ResourceList{
+			corev1.ResourceCPU:              userContainerCPU,
+			corev1.ResourceEphemeralStorage: resource.MustParse("50Mi"),
+		},
+	}, {
+		name: "user request is kept",
+		resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+			},
+		},
+		request: &defaultRequest,
+		want: corev1.ResourceList{
+			corev1.ResourceCPU:              userContainerCPU,
+			corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+		},
+	}, {
+		name: "user limit above the default",
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+			},
+		},
+		request: &defaultRequest,
+		want: corev1.ResourceList{
+			corev1.ResourceCPU:              userContainerCPU,
+			corev1.ResourceEphemeralStorage: resource.MustParse("50Mi"),
+		},
+	}, {
+		name: "user limit below the default",
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("10Mi"),
+			},
+		},
+		request: &defaultRequest,
+		want: corev1.ResourceList{
+			corev1.ResourceCPU: userContainerCPU,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: v1alpha1.RevisionSpec{
+					Container: corev1.Container{
+						Image:     "busybox",
+						Resources: test.resources,
+					},
+				},
+			}
+			quantityComparer := cmp.Comparer(func(x, y resource.Quantity) bool {
+				return x.Cmp(y) == 0
+			})
+
+			cc := &config.Controller{UserContainerEphemeralStorageRequest: test.request}
+			podSpec := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, cc)
+			if diff := cmp.Diff(test.want, podSpec.Containers[0].Resources.Requests, quantityComparer); diff != "" {
+				t.Errorf("Resources.Requests (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakePodSpecUserSidecars(t *testing.T) {
+	sidecarProbe := &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+				Port: intstr.FromInt(9000),
+			},
+		},
+	}
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+		},
+		Spec: v1alpha1.RevisionSpec{
+			Containers: []corev1.Container{{
+				Image:          "log-shipper",
+				ReadinessProbe: sidecarProbe.DeepCopy(),
+				LivenessProbe:  sidecarProbe.DeepCopy(),
+			}, {
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+			}},
+		},
+	}
+
+	podSpec := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, &config.Controller{})
+
+	var names []string
+	for _, c := range podSpec.Containers {
+		names = append(names, c.Name)
+	}
+	if diff := cmp.Diff([]string{UserContainerName, QueueContainerName, UserSidecarNamePrefix + "0"}, names); diff != "" {
+		t.Errorf("Container names (-want, +got) = %v", diff)
+	}
+	if got, want := podSpec.Containers[0].Image, "busybox"; got != want {
+		t.Errorf("User container image = %q, want %q", got, want)
+	}
+	if got, want := podSpec.Containers[0].Ports[0].ContainerPort, int32(8888); got != want {
+		t.Errorf("User container port = %d, want %d", got, want)
+	}
+	sidecar := podSpec.Containers[2]
+	if got, want := sidecar.Image, "log-shipper"; got != want {
+		t.Errorf("Sidecar image = %q, want %q", got, want)
+	}
+	if diff := cmp.Diff([]corev1.VolumeMount{varLogVolumeMount}, sidecar.VolumeMounts); diff != "" {
+		t.Errorf("Sidecar volume mounts (-want, +got) = %v", diff)
+	}
+	// Unlike the user container's, sidecar probes keep the port they check.
+	if diff := cmp.Diff(sidecarProbe, sidecar.ReadinessProbe); diff != "" {
+		t.Errorf("Sidecar readiness probe (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff(sidecarProbe, sidecar.LivenessProbe); diff != "" {
+		t.Errorf("Sidecar liveness probe (-want, +got) = %v", diff)
+	}
+}
+
+func TestMakePodSpecVolumes(t *testing.T) {
+	secretVolume := corev1.Volume{
+		Name: "secret",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: "foo"},
+		},
+	}
+	secretMount := corev1.VolumeMount{
+		Name:      "secret",
+		MountPath: "/etc/secret",
+		ReadOnly:  true,
+	}
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+		},
+		Spec: v1alpha1.RevisionSpec{
+			Container: corev1.Container{
+				Image:        "busybox",
+				VolumeMounts: []corev1.VolumeMount{secretMount},
+			},
+			Volumes: []corev1.Volume{secretVolume},
+		},
+	}
+
+	podSpec := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, &config.Controller{})
+
+	if diff := cmp.Diff([]corev1.Volume{varLogVolume, secretVolume}, podSpec.Volumes); diff != "" {
+		t.Errorf("Pod volumes (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff([]corev1.VolumeMount{secretMount, varLogVolumeMount}, podSpec.Containers[0].VolumeMounts); diff != "" {
+		t.Errorf("User container volume mounts (-want, +got) = %v", diff)
+	}
+}
+
+func TestMakePodSpecMultiplePorts(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+		},
+		Spec: v1alpha1.RevisionSpec{
+			Container: corev1.Container{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					Name:          "metrics",
+					ContainerPort: 9091,
+				}, {
+					Name:          "h2c",
+					ContainerPort: 8888,
+				}},
+			},
+		},
+	}
+	podSpec := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, &config.Controller{})
+
+	want := []corev1.ContainerPort{{
+		Name:          v1alpha1.UserPortName,
+		ContainerPort: 8888,
+	}, {
+		Name:          "metrics",
+		ContainerPort: 9091,
+	}}
+	if diff := cmp.Diff(want, podSpec.Containers[0].Ports); diff != "" {
+		t.Errorf("user container Ports (-want, +got) = %v", diff)
+	}
+	if got, want := getUserPort(rev), int32(8888); got != want {
+		t.Errorf("getUserPort() = %d, want %d", got, want)
+	}
+}
+
+func TestMakeDeploymentInitialReplicas(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        int32
+	}{{
+		name: "no min scale",
+		want: 1,
+	}, {
+		name: "min scale",
+		annotations: map[string]string{
+			autoscaling.MinScaleAnnotationKey: "3",
+		},
+		want: 3,
+	}, {
+		name: "min scale of one",
+		annotations: map[string]string{
+			autoscaling.MinScaleAnnotationKey: "1",
+		},
+		want: 1,
+	}, {
+		name: "only max scale",
+		annotations: map[string]string{
+			autoscaling.MaxScaleAnnotationKey: "5",
+		},
+		want: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "foo",
+					Name:        "bar",
+					UID:         "1234",
+					Annotations: test.annotations,
+				},
+				Spec: v1alpha1.RevisionSpec{
+					Container: corev1.Container{
+						Image: "busybox",
+					},
+				},
+			}
+			got := MakeDeployment(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{},
+				&autoscaler.Config{}, &config.Controller{})
+			if *got.Spec.Replicas != test.want {
+				t.Errorf("Replicas = %d, want %d", *got.Spec.Replicas, test.want)
+			}
+		})
+	}
+}
+
+func TestMakeDeploymentImagePullSecrets(t *testing.T) {
+	secrets := []corev1.LocalObjectReference{{
+		Name: "registry-credentials",
+	}, {
+		Name: "other-registry-credentials",
+	}}
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+			UID:       "1234",
+		},
+		Spec: v1alpha1.RevisionSpec{
+			Container: corev1.Container{
+				Image: "private.registry.io/busybox",
+			},
+			ImagePullSecrets: secrets,
+		},
+	}
+	got := MakeDeployment(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{},
+		&autoscaler.Config{}, &config.Controller{})
+	if diff := cmp.Diff(secrets, got.Spec.Template.Spec.ImagePullSecrets); diff != "" {
+		t.Errorf("ImagePullSecrets (-want, +got) = %v", diff)
+	}
+}
+
+func TestMakeDeploymentServiceAccountName(t *testing.T) {
+	for _, name := range []string{"", "workload-identity"} {
+		rev := &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+			},
+			Spec: v1alpha1.RevisionSpec{
+				Container: corev1.Container{
+					Image: "busybox",
+				},
+				ServiceAccountName: name,
+			},
+		}
+		got := MakeDeployment(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{},
+			&autoscaler.Config{}, &config.Controller{})
+		// Left empty, the pods run as the namespace's default service account.
+		if got := got.Spec.Template.Spec.ServiceAccountName; got != name {
+			t.Errorf("ServiceAccountName = %q, want %q", got, name)
+		}
+	}
+}
+
+func TestMakePodSpecDefaultReadinessProbe(t *testing.T) {
+	userProbe := &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		probe   *corev1.Probe
+		enabled bool
+		want    *corev1.Probe
+	}{{
+		name: "no probe, disabled",
+		want: nil,
+	}, {
+		name:    "no probe, enabled",
+		enabled: true,
+		want: &corev1.Probe{
+			Handler: corev1.Handler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(v1alpha1.DefaultUserPort)),
+				},
+			},
+		},
+	}, {
+		name:    "user probe, enabled",
+		probe:   userProbe,
+		enabled: true,
+		want: &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/healthz",
+					Port: intstr.FromInt(v1alpha1.RequestQueuePort),
+				},
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+					UID:       "1234",
+				},
+				Spec: v1alpha1.RevisionSpec{
+					Container: corev1.Container{
+						Image:          "busybox",
+						ReadinessProbe: test.probe,
+					},
+				},
+			}
+			got := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{},
+				&config.Controller{DefaultReadinessProbe: test.enabled})
+			if diff := cmp.Diff(test.want, got.Containers[0].ReadinessProbe); diff != "" {
+				t.Errorf("ReadinessProbe (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakeDeploymentMinReadySeconds(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+			UID:       "1234",
+		},
+		Spec: v1alpha1.RevisionSpec{
+			Container: corev1.Container{
+				Image: "busybox",
+			},
+		},
+	}
+	got := MakeDeployment(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{},
+		&autoscaler.Config{}, &config.Controller{MinReadySeconds: 10})
+	if got.Spec.MinReadySeconds != 10 {
+		t.Errorf("MinReadySeconds = %d, want 10", got.Spec.MinReadySeconds)
+	}
+}
+
+func TestMakeDeploymentPrometheusAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     bool
+		annotations map[string]string
+		want        map[string]string
+	}{{
+		name: "disabled",
+		want: map[string]string{},
+	}, {
+		name:    "enabled",
+		enabled: true,
+		want: map[string]string{
+			"prometheus.io/scrape": "true",
+			"prometheus.io/port":   "9090",
+			"prometheus.io/path":   "/metrics",
+		},
+	}, {
+		name:    "enabled, user annotations kept",
+		enabled: true,
+		annotations: map[string]string{
+			"prometheus.io/scrape": "false",
+		},
+		want: map[string]string{
+			"prometheus.io/scrape": "false",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "foo",
+					Name:        "bar",
+					UID:         "1234",
+					Annotations: test.annotations,
+				},
+				Spec: v1alpha1.RevisionSpec{
+					Container: corev1.Container{
+						Image: "busybox",
+					},
+				},
+			}
+			got := MakeDeployment(rev, &logging.Config{}, defaultNetworkConfig,
+				&config.Observability{EnablePrometheusScrapeAnnotations: test.enabled},
+				&autoscaler.Config{}, &config.Controller{})
+			annotations := map[string]string{}
+			for k, v := range got.Spec.Template.Annotations {
+				if strings.HasPrefix(k, "prometheus.io/") {
+					annotations[k] = v
+				}
+			}
+			if diff := cmp.Diff(test.want, annotations); diff != "" {
+				t.Errorf("Prometheus annotations (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMakeDeploymentConfigChecksum(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+			UID:       "1234",
+		},
+		Spec: v1alpha1.RevisionSpec{
+			Container: corev1.Container{
+				Image: "busybox",
+			},
+		},
+	}
+	checksum := func(oc *config.Observability) (string, bool) {
+		d := MakeDeployment(rev, &logging.Config{}, defaultNetworkConfig, oc,
+			&autoscaler.Config{}, &config.Controller{})
+		v, ok := d.Spec.Template.Annotations[configChecksumAnnotation]
+		return v, ok
+	}
+
+	if v, ok := checksum(&config.Observability{}); ok {
+		t.Errorf("Unexpected %s = %q without /var/log collection", configChecksumAnnotation, v)
+	}
+
+	oc := &config.Observability{
+		EnableVarLogCollection:     true,
+		FluentdSidecarImage:        "fluentd",
+		FluentdSidecarOutputConfig: "<match **>\n@type stdout\n</match>",
+	}
+	before, ok := checksum(oc)
+	if !ok || before == "" {
+		t.Fatalf("Missing %s with /var/log collection", configChecksumAnnotation)
+	}
+	if again, _ := checksum(oc); again != before {
+		t.Errorf("%s = %q, then %q for the same config", configChecksumAnnotation, before, again)
+	}
+
+	oc.FluentdSidecarOutputConfig = "<match **>\n@type null\n</match>"
+	if after, _ := checksum(oc); after == before {
+		t.Errorf("%s = %q did not change with the config", configChecksumAnnotation, after)
+	}
+}
+
+func TestMakePodSpecSecurityContext(t *testing.T) {
+	yes, no := true, false
+	user, fsGroup := int64(1000), int64(2000)
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+			UID:       "1234",
+		},
+		Spec: v1alpha1.RevisionSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: 8888,
+				}},
+				SecurityContext: &corev1.SecurityContext{
+					ReadOnlyRootFilesystem: &yes,
+				},
+			}, {
+				Image: "logshipper",
+				SecurityContext: &corev1.SecurityContext{
+					RunAsNonRoot: &no,
+				},
+			}},
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsUser:    &user,
+				RunAsNonRoot: &yes,
+				FSGroup:      &fsGroup,
+			},
+		},
+	}
+
+	got := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, &config.Controller{})
+
+	if diff := cmp.Diff(&corev1.PodSecurityContext{FSGroup: &fsGroup}, got.SecurityContext); diff != "" {
+		t.Errorf("Pod SecurityContext (-want, +got) = %v", diff)
+	}
+	for _, test := range []struct {
+		name string
+		want *corev1.SecurityContext
+	}{{
+		name: UserContainerName,
+		want: &corev1.SecurityContext{
+			RunAsUser:              &user,
+			RunAsNonRoot:           &yes,
+			ReadOnlyRootFilesystem: &yes,
+		},
+	}, {
+		name: QueueContainerName,
+		want: nil,
+	}, {
+		// The sidecar's own securityContext takes precedence.
+		name: UserSidecarNamePrefix + "0",
+		want: &corev1.SecurityContext{
+			RunAsUser:    &user,
+			RunAsNonRoot: &no,
+		},
+	}} {
+		var c *corev1.Container
+		for i := range got.Containers {
+			if got.Containers[i].Name == test.name {
+				c = &got.Containers[i]
+			}
+		}
+		if c == nil {
+			t.Errorf("Missing container %q", test.name)
+			continue
+		}
+		if diff := cmp.Diff(test.want, c.SecurityContext); diff != "" {
+			t.Errorf("%s SecurityContext (-want, +got) = %v", test.name, diff)
+		}
+	}
+}
+
+func TestMakePodSpecScheduling(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+			UID:       "1234",
+		},
+		Spec: v1alpha1.RevisionSpec{
+			Container: corev1.Container{
+				Image: "busybox",
+			},
+			NodeSelector: map[string]string{
+				"cloud.google.com/gke-accelerator": "nvidia-tesla-k80",
+			},
+			Tolerations: []corev1.Toleration{{
+				Key:      "nvidia.com/gpu",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}},
+		},
+	}
+
+	got := makePodSpec(rev, &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, &config.Controller{})
+
+	if diff := cmp.Diff(rev.Spec.NodeSelector, got.NodeSelector); diff != "" {
+		t.Errorf("NodeSelector (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff(rev.Spec.Tolerations, got.Tolerations); diff != "" {
+		t.Errorf("Tolerations (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/reconciler/v1alpha1/revision/config"
+)
+
+const (
+	modelVolumeName = "model"
+	modelDir        = "/var/lib/knative/model"
+)
+
+var (
+	modelVolume = corev1.Volume{
+		Name: modelVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+
+	modelVolumeMount = corev1.VolumeMount{
+		Name:      modelVolumeName,
+		MountPath: modelDir,
+	}
+
+	modelDirEnv = corev1.EnvVar{
+		Name:  "MODEL_DIR",
+		Value: modelDir,
+	}
+)
+
+// modelSource returns the source of the model to load into the Revision's
+// pods. Revisions opt in through an annotation, but nothing is loaded unless
+// the operator has configured a model loader image.
+func modelSource(rev *v1alpha1.Revision, controllerConfig *config.Controller) (string, bool) {
+	if controllerConfig.ModelLoaderImage == "" {
+		return "", false
+	}
+	source, ok := rev.Annotations[serving.ModelSourceAnnotationKey]
+	return source, ok && source != ""
+}
+
+func makeModelLoaderContainer(source string, controllerConfig *config.Controller) *corev1.Container {
+	return &corev1.Container{
+		Name:  ModelLoaderContainerName,
+		Image: controllerConfig.ModelLoaderImage,
+		Env: []corev1.EnvVar{{
+			Name:  "MODEL_SOURCE",
+			Value: source,
+		}, modelDirEnv},
+		VolumeMounts: []corev1.VolumeMount{
+			modelVolumeMount,
+		},
+	}
+}
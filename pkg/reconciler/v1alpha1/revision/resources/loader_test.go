@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knative/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/autoscaler"
+	"github.com/knative/serving/pkg/reconciler/v1alpha1/revision/config"
+)
+
+func TestMakePodSpecModelLoader(t *testing.T) {
+	rev := func(annotations map[string]string) *v1alpha1.Revision {
+		return &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "foo",
+				Name:        "bar",
+				UID:         "1234",
+				Annotations: annotations,
+			},
+			Spec: v1alpha1.RevisionSpec{
+				Container: corev1.Container{
+					Image: "busybox",
+				},
+			},
+		}
+	}
+	withSource := map[string]string{
+		serving.ModelSourceAnnotationKey: "gs://bucket/model",
+	}
+
+	t.Run("requested and enabled", func(t *testing.T) {
+		podSpec := makePodSpec(rev(withSource), &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{},
+			&config.Controller{ModelLoaderImage: "loader"})
+
+		wantInit := []corev1.Container{{
+			Name:  ModelLoaderContainerName,
+			Image: "loader",
+			Env: []corev1.EnvVar{{
+				Name:  "MODEL_SOURCE",
+				Value: "gs://bucket/model",
+			}, {
+				Name:  "MODEL_DIR",
+				Value: "/var/lib/knative/model",
+			}},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "model",
+				MountPath: "/var/lib/knative/model",
+			}},
+		}}
+		// The loader is an init container, so it completes before the user
+		// container starts.
+		if diff := cmp.Diff(wantInit, podSpec.InitContainers); diff != "" {
+			t.Errorf("InitContainers (-want, +got) = %v", diff)
+		}
+		if diff := cmp.Diff(modelVolume, podSpec.Volumes[len(podSpec.Volumes)-1]); diff != "" {
+			t.Errorf("Volumes (-want, +got) = %v", diff)
+		}
+
+		user := podSpec.Containers[0]
+		if diff := cmp.Diff(modelVolumeMount, user.VolumeMounts[len(user.VolumeMounts)-1]); diff != "" {
+			t.Errorf("user container VolumeMounts (-want, +got) = %v", diff)
+		}
+		if diff := cmp.Diff(modelDirEnv, user.Env[len(user.Env)-1]); diff != "" {
+			t.Errorf("user container Env (-want, +got) = %v", diff)
+		}
+	})
+
+	for _, test := range []struct {
+		name        string
+		annotations map[string]string
+		cc          *config.Controller
+	}{{
+		name:        "requested but disabled by the operator",
+		annotations: withSource,
+		cc:          &config.Controller{},
+	}, {
+		name: "not requested",
+		cc:   &config.Controller{ModelLoaderImage: "loader"},
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			podSpec := makePodSpec(rev(test.annotations), &logging.Config{}, defaultNetworkConfig, &config.Observability{}, &autoscaler.Config{}, test.cc)
+			if len(podSpec.InitContainers) != 0 {
+				t.Errorf("InitContainers = %v, want none", podSpec.InitContainers)
+			}
+			for _, v := range podSpec.Volumes {
+				if v.Name == modelVolumeName {
+					t.Errorf("Volumes = %v, want no %q volume", podSpec.Volumes, modelVolumeName)
+				}
+			}
+		})
+	}
+}
@@ -33,6 +33,14 @@ func makeLabels(revision *v1alpha1.Revision) map[string]string {
 		labels[k] = v
 	}
 
+	// Revisions stamped out by a Configuration normally carry its name as a
+	// label already, fall back on the owner reference for those that don't.
+	if _, ok := labels[serving.ConfigurationLabelKey]; !ok {
+		if owner := metav1.GetControllerOf(revision); owner != nil && owner.Kind == "Configuration" {
+			labels[serving.ConfigurationLabelKey] = owner.Name
+		}
+	}
+
 	// If users don't specify an app: label we will automatically
 	// populate it with the revision name to get the benefit of richer
 	// tracing information.
@@ -27,6 +27,7 @@ import (
 )
 
 func TestMakeLabels(t *testing.T) {
+	isController := true
 	tests := []struct {
 		name string
 		rev  *v1alpha1.Revision
@@ -65,6 +66,51 @@ func TestMakeLabels(t *testing.T) {
 			"ooga":                   "booga",
 			"unicorn":                "rainbows",
 		},
+	}, {
+		name: "label from owning configuration",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: v1alpha1.SchemeGroupVersion.String(),
+					Kind:       "Configuration",
+					Name:       "cfg",
+					Controller: &isController,
+				}},
+			},
+		},
+		want: map[string]string{
+			serving.RevisionLabelKey:      "bar",
+			serving.RevisionUID:           "1234",
+			serving.ConfigurationLabelKey: "cfg",
+			AppLabelKey:                   "bar",
+		},
+	}, {
+		name: "configuration label is not overridden by owner",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Labels: map[string]string{
+					serving.ConfigurationLabelKey: "other",
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: v1alpha1.SchemeGroupVersion.String(),
+					Kind:       "Configuration",
+					Name:       "cfg",
+					Controller: &isController,
+				}},
+			},
+		},
+		want: map[string]string{
+			serving.RevisionLabelKey:      "bar",
+			serving.RevisionUID:           "1234",
+			serving.ConfigurationLabelKey: "other",
+			AppLabelKey:                   "bar",
+		},
 	}, {
 		name: "override app label key",
 		rev: &v1alpha1.Revision{
@@ -18,6 +18,7 @@ package names
 
 import (
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/knative/serving/pkg/reconciler"
 )
 
 func Deployment(rev *v1alpha1.Revision) string {
@@ -34,8 +35,10 @@ func KPA(rev *v1alpha1.Revision) string {
 	return rev.Name
 }
 
+// K8sService returns the name of the Revision's Service. Unlike the names of
+// the other children, which may be DNS subdomains, it must fit in a DNS label.
 func K8sService(rev *v1alpha1.Revision) string {
-	return rev.Name + "-service"
+	return reconciler.GetServingK8SServiceNameForObj(rev.Name)
 }
 
 func FluentdConfigMap(rev *v1alpha1.Revision) string {
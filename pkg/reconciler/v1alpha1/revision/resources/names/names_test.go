@@ -17,11 +17,12 @@ limitations under the License.
 package names
 
 import (
+	"strings"
 	"testing"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 func TestNamer(t *testing.T) {
@@ -86,3 +87,64 @@ func TestNamer(t *testing.T) {
 		})
 	}
 }
+
+func TestNamesDoNotCollide(t *testing.T) {
+	// Each kind of child resource gets its own suffix, so two distinct
+	// Revisions can never derive the same name for the same kind, even
+	// when one Revision name is the other's with a suffix appended.
+	tests := []struct {
+		name string
+		a, b string
+	}{{
+		name: "suffixed revision name",
+		a:    "foo",
+		b:    "foo-deployment",
+	}, {
+		name: "service suffixed revision name",
+		a:    "foo",
+		b:    "foo-service",
+	}, {
+		name: "unrelated revision names",
+		a:    "foo",
+		b:    "bar",
+	}}
+
+	namers := map[string]func(*v1alpha1.Revision) string{
+		"Deployment":       Deployment,
+		"ImageCache":       ImageCache,
+		"KPA":              KPA,
+		"K8sService":       K8sService,
+		"FluentdConfigMap": FluentdConfigMap,
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: test.a}}
+			b := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: test.b}}
+			for kind, namer := range namers {
+				if got := namer(a); got == namer(b) {
+					t.Errorf("%s(%q) = %s(%q) = %q", kind, test.a, kind, test.b, got)
+				}
+			}
+		})
+	}
+}
+
+func TestServiceNamesOfLongRevisionsFit(t *testing.T) {
+	// The longest Revision names validation allows, differing only at the end.
+	a := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 62) + "a"}}
+	b := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 62) + "b"}}
+	got := K8sService(a)
+	if errs := validation.IsDNS1035Label(got); len(errs) != 0 {
+		t.Errorf("K8sService(%q) = %q, not a DNS label: %v", a.Name, got, errs)
+	}
+	if got == K8sService(b) {
+		t.Errorf("K8sService(%q) = K8sService(%q) = %q", a.Name, b.Name, got)
+	}
+
+	// The names of the other children may be DNS subdomains, and are kept
+	// as they were so that upgrades don't orphan existing resources.
+	if got, want := Deployment(a), a.Name+"-deployment"; got != want {
+		t.Errorf("Deployment(%q) = %q, want %q", a.Name, got, want)
+	}
+}
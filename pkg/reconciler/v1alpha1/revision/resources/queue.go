@@ -35,31 +35,43 @@ var (
 			corev1.ResourceName("cpu"): queueContainerCPU,
 		},
 	}
-	queuePorts = []corev1.ContainerPort{{
+)
+
+// makeQueuePorts returns the ports of the queue sidecar.
+func makeQueuePorts(networkConfig *config.Network) []corev1.ContainerPort {
+	return []corev1.ContainerPort{{
 		Name:          v1alpha1.RequestQueuePortName,
-		ContainerPort: int32(v1alpha1.RequestQueuePort),
+		ContainerPort: int32(networkConfig.QueueProxyPorts.Serving),
 	}, {
 		// Provides health checks and lifecycle hooks.
 		Name:          v1alpha1.RequestQueueAdminPortName,
-		ContainerPort: int32(v1alpha1.RequestQueueAdminPort),
+		ContainerPort: int32(networkConfig.QueueProxyPorts.Admin),
 	}, {
 		Name:          v1alpha1.RequestQueueMetricsPortName,
-		ContainerPort: int32(v1alpha1.RequestQueueMetricsPort),
+		ContainerPort: int32(networkConfig.QueueProxyPorts.Metrics),
 	}}
+}
+
+// makeQueueLifecycle returns the lifecycle of the queue sidecar.
+func makeQueueLifecycle(networkConfig *config.Network) *corev1.Lifecycle {
 	// This handler (1) marks the service as not ready and (2)
 	// adds a small delay before the container is killed.
-	queueLifecycle = &corev1.Lifecycle{
+	return &corev1.Lifecycle{
 		PreStop: &corev1.Handler{
 			HTTPGet: &corev1.HTTPGetAction{
-				Port: intstr.FromInt(v1alpha1.RequestQueueAdminPort),
+				Port: intstr.FromInt(networkConfig.QueueProxyPorts.Admin),
 				Path: queue.RequestQueueQuitPath,
 			},
 		},
 	}
-	queueReadinessProbe = &corev1.Probe{
+}
+
+// makeQueueReadinessProbe returns the readiness probe of the queue sidecar.
+func makeQueueReadinessProbe(networkConfig *config.Network) *corev1.Probe {
+	return &corev1.Probe{
 		Handler: corev1.Handler{
 			HTTPGet: &corev1.HTTPGetAction{
-				Port: intstr.FromInt(v1alpha1.RequestQueueAdminPort),
+				Port: intstr.FromInt(networkConfig.QueueProxyPorts.Admin),
 				Path: queue.RequestQueueHealthPath,
 			},
 		},
@@ -69,11 +81,11 @@ var (
 		// sacrifice for a low rate of 503s.
 		PeriodSeconds: 1,
 	}
-)
+}
 
 // makeQueueContainer creates the container spec for queue sidecar.
-func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, autoscalerConfig *autoscaler.Config,
-	controllerConfig *config.Controller) *corev1.Container {
+func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, networkConfig *config.Network,
+	autoscalerConfig *autoscaler.Config, controllerConfig *config.Controller) *corev1.Container {
 	configName := ""
 	if owner := metav1.GetControllerOf(rev); owner != nil && owner.Kind == "Configuration" {
 		configName = owner.Name
@@ -91,9 +103,9 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, a
 		Name:           QueueContainerName,
 		Image:          controllerConfig.QueueSidecarImage,
 		Resources:      queueResources,
-		Ports:          queuePorts,
-		Lifecycle:      queueLifecycle,
-		ReadinessProbe: queueReadinessProbe,
+		Ports:          makeQueuePorts(networkConfig),
+		Lifecycle:      makeQueueLifecycle(networkConfig),
+		ReadinessProbe: makeQueueReadinessProbe(networkConfig),
 		Env: []corev1.EnvVar{{
 			Name:  "SERVING_NAMESPACE",
 			Value: rev.Namespace,
@@ -131,6 +143,15 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, a
 		}, {
 			Name:  "USER_PORT",
 			Value: strconv.Itoa(int(userPort)),
+		}, {
+			Name:  "QUEUE_SERVING_PORT",
+			Value: strconv.Itoa(networkConfig.QueueProxyPorts.Serving),
+		}, {
+			Name:  "QUEUE_ADMIN_PORT",
+			Value: strconv.Itoa(networkConfig.QueueProxyPorts.Admin),
+		}, {
+			Name:  "QUEUE_METRICS_PORT",
+			Value: strconv.Itoa(networkConfig.QueueProxyPorts.Metrics),
 		}},
 	}
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/knative/pkg/logging"
+	apisconfig "github.com/knative/serving/pkg/apis/config"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
 	"github.com/knative/serving/pkg/reconciler/v1alpha1/revision/config"
@@ -67,9 +68,9 @@ func TestMakeQueueContainer(t *testing.T) {
 			// These are effectively constant
 			Name:           QueueContainerName,
 			Resources:      queueResources,
-			Ports:          queuePorts,
-			Lifecycle:      queueLifecycle,
-			ReadinessProbe: queueReadinessProbe,
+			Ports:          makeQueuePorts(defaultNetworkConfig),
+			Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+			ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 			// These changed based on the Revision and configs passed in.
 			Env: []corev1.EnvVar{{
 				Name:  "SERVING_NAMESPACE",
@@ -106,6 +107,15 @@ func TestMakeQueueContainer(t *testing.T) {
 			}, {
 				Name:  "USER_PORT",
 				Value: strconv.Itoa(v1alpha1.DefaultUserPort),
+			}, {
+				Name:  "QUEUE_SERVING_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+			}, {
+				Name:  "QUEUE_ADMIN_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+			}, {
+				Name:  "QUEUE_METRICS_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 			}},
 		},
 	}, {
@@ -134,9 +144,9 @@ func TestMakeQueueContainer(t *testing.T) {
 			// These are effectively constant
 			Name:           QueueContainerName,
 			Resources:      queueResources,
-			Ports:          queuePorts,
-			Lifecycle:      queueLifecycle,
-			ReadinessProbe: queueReadinessProbe,
+			Ports:          makeQueuePorts(defaultNetworkConfig),
+			Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+			ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 			// These changed based on the Revision and configs passed in.
 			Image: "alpine",
 			Env: []corev1.EnvVar{{
@@ -174,6 +184,15 @@ func TestMakeQueueContainer(t *testing.T) {
 			}, {
 				Name:  "USER_PORT",
 				Value: strconv.Itoa(v1alpha1.DefaultUserPort),
+			}, {
+				Name:  "QUEUE_SERVING_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+			}, {
+				Name:  "QUEUE_ADMIN_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+			}, {
+				Name:  "QUEUE_METRICS_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 			}},
 		},
 	}, {
@@ -207,9 +226,9 @@ func TestMakeQueueContainer(t *testing.T) {
 			// These are effectively constant
 			Name:           QueueContainerName,
 			Resources:      queueResources,
-			Ports:          queuePorts,
-			Lifecycle:      queueLifecycle,
-			ReadinessProbe: queueReadinessProbe,
+			Ports:          makeQueuePorts(defaultNetworkConfig),
+			Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+			ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 			// These changed based on the Revision and configs passed in.
 			Env: []corev1.EnvVar{{
 				Name:  "SERVING_NAMESPACE",
@@ -246,6 +265,15 @@ func TestMakeQueueContainer(t *testing.T) {
 			}, {
 				Name:  "USER_PORT",
 				Value: strconv.Itoa(v1alpha1.DefaultUserPort),
+			}, {
+				Name:  "QUEUE_SERVING_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+			}, {
+				Name:  "QUEUE_ADMIN_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+			}, {
+				Name:  "QUEUE_METRICS_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 			}},
 		},
 	}, {
@@ -277,9 +305,9 @@ func TestMakeQueueContainer(t *testing.T) {
 			// These are effectively constant
 			Name:           QueueContainerName,
 			Resources:      queueResources,
-			Ports:          queuePorts,
-			Lifecycle:      queueLifecycle,
-			ReadinessProbe: queueReadinessProbe,
+			Ports:          makeQueuePorts(defaultNetworkConfig),
+			Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+			ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 			// These changed based on the Revision and configs passed in.
 			Env: []corev1.EnvVar{{
 				Name:  "SERVING_NAMESPACE",
@@ -316,6 +344,15 @@ func TestMakeQueueContainer(t *testing.T) {
 			}, {
 				Name:  "USER_PORT",
 				Value: strconv.Itoa(v1alpha1.DefaultUserPort),
+			}, {
+				Name:  "QUEUE_SERVING_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+			}, {
+				Name:  "QUEUE_ADMIN_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+			}, {
+				Name:  "QUEUE_METRICS_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 			}},
 		},
 	}, {
@@ -342,9 +379,9 @@ func TestMakeQueueContainer(t *testing.T) {
 			// These are effectively constant
 			Name:           QueueContainerName,
 			Resources:      queueResources,
-			Ports:          queuePorts,
-			Lifecycle:      queueLifecycle,
-			ReadinessProbe: queueReadinessProbe,
+			Ports:          makeQueuePorts(defaultNetworkConfig),
+			Lifecycle:      makeQueueLifecycle(defaultNetworkConfig),
+			ReadinessProbe: makeQueueReadinessProbe(defaultNetworkConfig),
 			// These changed based on the Revision and configs passed in.
 			Env: []corev1.EnvVar{{
 				Name:  "SERVING_NAMESPACE",
@@ -381,16 +418,73 @@ func TestMakeQueueContainer(t *testing.T) {
 			}, {
 				Name:  "USER_PORT",
 				Value: strconv.Itoa(v1alpha1.DefaultUserPort),
+			}, {
+				Name:  "QUEUE_SERVING_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Serving),
+			}, {
+				Name:  "QUEUE_ADMIN_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Admin),
+			}, {
+				Name:  "QUEUE_METRICS_PORT",
+				Value: strconv.Itoa(defaultNetworkConfig.QueueProxyPorts.Metrics),
 			}},
 		},
 	}}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			got := makeQueueContainer(test.rev, test.lc, test.ac, test.cc)
+			got := makeQueueContainer(test.rev, test.lc, defaultNetworkConfig, test.ac, test.cc)
 			if diff := cmp.Diff(test.want, got, cmpopts.IgnoreUnexported(resource.Quantity{})); diff != "" {
 				t.Errorf("makeQueueContainer (-want, +got) = %v", diff)
 			}
 		})
 	}
 }
+
+func TestMakeQueueContainerConfiguredPorts(t *testing.T) {
+	nc := &config.Network{
+		QueueProxyPorts: apisconfig.QueueProxyPorts{
+			Serving: 18012,
+			Admin:   18022,
+			Metrics: 19090,
+		},
+	}
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+		},
+	}
+
+	got := makeQueueContainer(rev, &logging.Config{}, nc, &autoscaler.Config{}, &config.Controller{})
+
+	wantPorts := []corev1.ContainerPort{{
+		Name:          v1alpha1.RequestQueuePortName,
+		ContainerPort: 18012,
+	}, {
+		Name:          v1alpha1.RequestQueueAdminPortName,
+		ContainerPort: 18022,
+	}, {
+		Name:          v1alpha1.RequestQueueMetricsPortName,
+		ContainerPort: 19090,
+	}}
+	if diff := cmp.Diff(wantPorts, got.Ports); diff != "" {
+		t.Errorf("Ports (-want, +got) = %v", diff)
+	}
+	if got, want := got.ReadinessProbe.HTTPGet.Port.IntValue(), 18022; got != want {
+		t.Errorf("ReadinessProbe port = %d, wanted %d", got, want)
+	}
+	env := map[string]string{}
+	for _, e := range got.Env {
+		env[e.Name] = e.Value
+	}
+	for name, want := range map[string]string{
+		"QUEUE_SERVING_PORT": "18012",
+		"QUEUE_ADMIN_PORT":   "18022",
+		"QUEUE_METRICS_PORT": "19090",
+	} {
+		if got := env[name]; got != want {
+			t.Errorf("%s = %q, wanted %q", name, got, want)
+		}
+	}
+}
@@ -42,6 +42,17 @@ var (
 	}}
 )
 
+// makeServicePorts returns the ports of the Revision's Service, whose
+// external port is named after the protocol the Revision speaks.
+func makeServicePorts(rev *v1alpha1.Revision) []corev1.ServicePort {
+	if rev.GetProtocol() != v1alpha1.RevisionProtocolH2C {
+		return servicePorts
+	}
+	ports := append([]corev1.ServicePort(nil), servicePorts...)
+	ports[0].Name = ServicePortNameH2C
+	return ports
+}
+
 // MakeK8sService creates a Kubernetes Service that targets all pods with the same
 // serving.RevisionLabelKey label. Traffic is routed to queue-proxy port.
 func MakeK8sService(rev *v1alpha1.Revision) *corev1.Service {
@@ -56,7 +67,7 @@ func MakeK8sService(rev *v1alpha1.Revision) *corev1.Service {
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(rev)},
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: servicePorts,
+			Ports: makeServicePorts(rev),
 			Selector: map[string]string{
 				serving.RevisionLabelKey: rev.Name,
 			},
@@ -116,3 +116,68 @@ func TestMakeK8sService(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeK8sServicePortName(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		ports       []corev1.ContainerPort
+		want        string
+	}{{
+		name: "default protocol",
+		want: ServicePortName,
+	}, {
+		name: "h2c port",
+		ports: []corev1.ContainerPort{{
+			Name:          "h2c",
+			ContainerPort: 8888,
+		}},
+		want: ServicePortNameH2C,
+	}, {
+		name: "h2c override of an http1 port",
+		annotations: map[string]string{
+			serving.ProtocolAnnotationKey: "h2c",
+		},
+		ports: []corev1.ContainerPort{{
+			Name:          "http1",
+			ContainerPort: 8888,
+		}},
+		want: ServicePortNameH2C,
+	}, {
+		name: "http1 override of an h2c port",
+		annotations: map[string]string{
+			serving.ProtocolAnnotationKey: "http1",
+		},
+		ports: []corev1.ContainerPort{{
+			Name:          "h2c",
+			ContainerPort: 8888,
+		}},
+		want: ServicePortName,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "foo",
+					Name:        "bar",
+					UID:         "1234",
+					Annotations: test.annotations,
+				},
+				Spec: v1alpha1.RevisionSpec{
+					Container: corev1.Container{
+						Image: "busybox",
+						Ports: test.ports,
+					},
+				},
+			}
+			got := MakeK8sService(rev)
+			if got.Spec.Ports[0].Name != test.want {
+				t.Errorf("Port name = %q, want %q", got.Spec.Ports[0].Name, test.want)
+			}
+			if servicePorts[0].Name != ServicePortName {
+				t.Errorf("servicePorts was modified, port name = %q", servicePorts[0].Name)
+			}
+		})
+	}
+}
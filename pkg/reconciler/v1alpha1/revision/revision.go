@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn/k8schain"
 	cachinginformers "github.com/knative/caching/pkg/client/informers/externalversions/caching/v1alpha1"
@@ -50,6 +51,7 @@ import (
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 )
 
 const (
@@ -92,12 +94,21 @@ type Reconciler struct {
 	serviceLister       corev1listers.ServiceLister
 	endpointsLister     corev1listers.EndpointsLister
 	configMapLister     corev1listers.ConfigMapLister
+	nodeLister          corev1listers.NodeLister
 
 	buildInformerFactory duck.InformerFactory
 
 	tracker     tracker.Interface
 	resolver    resolver
 	configStore configStore
+
+	// enqueueAfter schedules the given Revision to be reconciled again
+	// after the given delay.
+	enqueueAfter func(obj interface{}, after time.Duration)
+
+	// numRequeues returns how many times in a row the given key has been
+	// retried after failing to reconcile.
+	numRequeues func(key string) int
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -117,6 +128,7 @@ func NewController(
 	serviceInformer corev1informers.ServiceInformer,
 	endpointsInformer corev1informers.EndpointsInformer,
 	configMapInformer corev1informers.ConfigMapInformer,
+	nodeInformer corev1informers.NodeInformer,
 	buildInformerFactory duck.InformerFactory,
 ) *controller.Impl {
 	transport := http.DefaultTransport
@@ -135,12 +147,24 @@ func NewController(
 		serviceLister:       serviceInformer.Lister(),
 		endpointsLister:     endpointsInformer.Lister(),
 		configMapLister:     configMapInformer.Lister(),
+		nodeLister:          nodeInformer.Lister(),
 		resolver: &digestResolver{
 			client:    opt.KubeClientSet,
 			transport: transport,
 		},
 	}
 	impl := controller.NewImpl(c, c.Logger, "Revisions", reconciler.MustNewStatsReporter("Revisions", c.Logger))
+	c.enqueueAfter = func(obj interface{}, after time.Duration) {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			c.Logger.Errorf("Unable to compute key for %v: %v", obj, err)
+			return
+		}
+		impl.WorkQueue.AddAfter(key, after)
+	}
+	c.numRequeues = func(key string) int {
+		return impl.WorkQueue.NumRequeues(key)
+	}
 
 	// Set up an event handler for when the resource types of interest change
 	c.Logger.Info("Setting up event handlers")
@@ -270,9 +294,23 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 		logger.Warn("Failed to update revision status", zap.Error(err))
 		c.Recorder.Eventf(rev, corev1.EventTypeWarning, "UpdateFailed",
 			"Failed to update status for Revision %q: %v", rev.Name, err)
+		return c.giveUpAfterRetries(ctx, rev, key, err)
+	}
+	return c.giveUpAfterRetries(ctx, rev, key, err)
+}
+
+// giveUpAfterRetries swallows the given reconcile error once the key has been
+// retried the configured number of times, so that the work queue forgets it
+// instead of retrying a hopeless Revision forever.
+func (c *Reconciler) giveUpAfterRetries(ctx context.Context, rev *v1alpha1.Revision, key string, err error) error {
+	max := config.FromContext(ctx).Controller.MaxReconcileRetries
+	if err == nil || max == 0 || c.numRequeues(key) < max {
 		return err
 	}
-	return err
+	commonlogging.FromContext(ctx).Errorf("Giving up on revision after %d retries: %v", max, err)
+	c.Recorder.Eventf(rev, corev1.EventTypeWarning, "ReconcileRetriesExhausted",
+		"Giving up on Revision %q after %d retries: %v", rev.Name, max, err)
+	return nil
 }
 
 func (c *Reconciler) reconcileBuild(ctx context.Context, rev *v1alpha1.Revision) error {
@@ -334,12 +372,13 @@ func (c *Reconciler) reconcileDigest(ctx context.Context, rev *v1alpha1.Revision
 	opt := k8schain.Options{
 		Namespace:          rev.Namespace,
 		ServiceAccountName: rev.Spec.ServiceAccountName,
-		// ImagePullSecrets: Not possible via RevisionSpec, since we
-		// don't expose such a field.
 	}
-	digest, err := c.resolver.Resolve(rev.Spec.Container.Image, opt, cfgs.Controller.RegistriesSkippingTagResolving)
+	for _, s := range rev.Spec.ImagePullSecrets {
+		opt.ImagePullSecrets = append(opt.ImagePullSecrets, s.Name)
+	}
+	digest, err := c.resolver.Resolve(rev.Spec.ServingContainer().Image, opt, cfgs.Controller.RegistriesSkippingTagResolving)
 	if err != nil {
-		rev.Status.MarkContainerMissing(v1alpha1.RevisionContainerMissingMessage(rev.Spec.Container.Image, err.Error()))
+		rev.Status.MarkContainerMissing(v1alpha1.RevisionContainerMissingMessage(rev.Spec.ServingContainer().Image, err.Error()))
 		return err
 	}
 
@@ -368,26 +407,25 @@ func (c *Reconciler) reconcile(ctx context.Context, rev *v1alpha1.Revision) erro
 	if bc == nil || bc.Status == corev1.ConditionTrue {
 		// There is no build, or the build completed successfully.
 
-		phases := []struct {
+		type phase struct {
 			name string
 			f    func(context.Context, *v1alpha1.Revision) error
-		}{{
-			name: "image digest",
-			f:    c.reconcileDigest,
-		}, {
-			name: "user deployment",
-			f:    c.reconcileDeployment,
-		}, {
-			name: "user k8s service",
-			f:    c.reconcileService,
-		}, {
+		}
+		var (
+			digest     = phase{name: "image digest", f: c.reconcileDigest}
+			deployment = phase{name: "user deployment", f: c.reconcileDeployment}
+			service    = phase{name: "user k8s service", f: c.reconcileService}
 			// Ensures our namespace has the configuration for the fluentd sidecar.
-			name: "fluentd configmap",
-			f:    c.reconcileFluentdConfigMap,
-		}, {
-			name: "KPA",
-			f:    c.reconcileKPA,
-		}}
+			fluentd = phase{name: "fluentd configmap", f: c.reconcileFluentdConfigMap}
+			kpa     = phase{name: "KPA", f: c.reconcileKPA}
+		)
+
+		phases := []phase{digest, deployment, service, fluentd, kpa}
+		// Operators may choose to have the Service exist before the pods
+		// that back it, at the cost of it briefly having no endpoints.
+		if config.FromContext(ctx).Controller.CreateServiceBeforeDeployment {
+			phases = []phase{digest, service, deployment, fluentd, kpa}
+		}
 
 		for _, phase := range phases {
 			if err := phase.f(ctx, rev); err != nil {
@@ -395,6 +433,7 @@ func (c *Reconciler) reconcile(ctx context.Context, rev *v1alpha1.Revision) erro
 				return err
 			}
 		}
+		rev.Status.ClearResourceFailed()
 	}
 
 	return nil
@@ -422,12 +461,36 @@ func (c *Reconciler) updateStatus(desired *v1alpha1.Revision) (*v1alpha1.Revisio
 	if err != nil {
 		return nil, err
 	}
-	// If there's nothing to update, just return.
-	if reflect.DeepEqual(rev.Status, desired.Status) {
-		return rev, nil
+	client := c.ServingClientSet.ServingV1alpha1().Revisions(desired.Namespace)
+	attempt := 0
+	// Another actor updating the Revision between our read and our update
+	// is benign, retry with its version rather than failing the reconcile.
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if attempt > 0 {
+			// The informer's copy may not have caught up yet.
+			latest, err := client.Get(desired.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			rev = latest
+		}
+		attempt++
+		// If there's nothing to update, just return.
+		if reflect.DeepEqual(rev.Status, desired.Status) {
+			return nil
+		}
+		// Don't modify the informers copy
+		existing := rev.DeepCopy()
+		existing.Status = desired.Status
+		updated, err := client.UpdateStatus(existing)
+		if err != nil {
+			return err
+		}
+		rev = updated
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	// Don't modify the informers copy
-	existing := rev.DeepCopy()
-	existing.Status = desired.Status
-	return c.ServingClientSet.ServingV1alpha1().Revisions(desired.Namespace).UpdateStatus(existing)
+	return rev, nil
 }
@@ -38,6 +38,7 @@ import (
 	"github.com/knative/pkg/configmap"
 	ctrl "github.com/knative/pkg/controller"
 	"github.com/knative/pkg/kmeta"
+	"github.com/knative/serving/pkg/apis/autoscaling"
 	kpav1alpha1 "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
@@ -169,6 +170,7 @@ func newTestControllerWithConfig(t *testing.T, controllerConfig *config.Controll
 		kubeInformer.Core().V1().Services(),
 		kubeInformer.Core().V1().Endpoints(),
 		kubeInformer.Core().V1().ConfigMaps(),
+		kubeInformer.Core().V1().Nodes(),
 		buildInformerFactory,
 	)
 
@@ -360,6 +362,44 @@ func (r *errorResolver) Resolve(_ string, _ k8schain.Options, _ map[string]struc
 	return "", errors.New(r.error)
 }
 
+// optionsResolver records the options it is asked to resolve with.
+type optionsResolver struct {
+	opt k8schain.Options
+}
+
+func (r *optionsResolver) Resolve(_ string, opt k8schain.Options, _ map[string]struct{}) (string, error) {
+	r.opt = opt
+	return "foo@sha256:deadbeef", nil
+}
+
+func TestResolutionWithImagePullSecrets(t *testing.T) {
+	kubeClient, servingClient, cachingClient, _, controller, kubeInformer, servingInformer, cachingInformer, _, _ := newTestController(t, nil)
+
+	resolver := &optionsResolver{}
+	controller.Reconciler.(*Reconciler).resolver = resolver
+
+	rev := getTestRevision()
+	rev.Spec.ServiceAccountName = "builder"
+	rev.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{
+		Name: "registry-a",
+	}, {
+		Name: "registry-b",
+	}}
+	config := getTestConfiguration()
+	rev.OwnerReferences = append(rev.OwnerReferences, *kmeta.NewControllerRef(config))
+
+	createRevision(t, kubeClient, kubeInformer, servingClient, servingInformer, cachingClient, cachingInformer, controller, rev)
+
+	want := k8schain.Options{
+		Namespace:          testNamespace,
+		ServiceAccountName: "builder",
+		ImagePullSecrets:   []string{"registry-a", "registry-b"},
+	}
+	if diff := cmp.Diff(want, resolver.opt); diff != "" {
+		t.Errorf("Unexpected resolve options diff (-want +got): %v", diff)
+	}
+}
+
 func TestResolutionFailed(t *testing.T) {
 	kubeClient, servingClient, cachingClient, _, controller, kubeInformer, servingInformer, cachingInformer, _, _ := newTestController(t, nil)
 
@@ -395,6 +435,40 @@ func TestResolutionFailed(t *testing.T) {
 	}
 }
 
+func TestInvalidScaleAnnotation(t *testing.T) {
+	kubeClient, servingClient, cachingClient, _, controller, kubeInformer, servingInformer, cachingInformer, _, _ := newTestController(t, nil)
+
+	// A Revision created before its scale annotations were validated.
+	rev := getTestRevision()
+	rev.Annotations = map[string]string{
+		autoscaling.MaxScaleAnnotationKey: "ten",
+	}
+	config := getTestConfiguration()
+	rev.OwnerReferences = append(rev.OwnerReferences, *kmeta.NewControllerRef(config))
+
+	createRevision(t, kubeClient, kubeInformer, servingClient, servingInformer, cachingClient, cachingInformer, controller, rev)
+
+	rev, err := servingClient.ServingV1alpha1().Revisions(testNamespace).Get(rev.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get revision: %v", err)
+	}
+
+	for _, ct := range []duckv1alpha1.ConditionType{"ResourcesAvailable", "Ready"} {
+		got := rev.Status.GetCondition(ct)
+		want := &duckv1alpha1.Condition{
+			Type:               ct,
+			Status:             corev1.ConditionFalse,
+			Reason:             "InvalidScaleAnnotation",
+			Message:            v1alpha1.RevisionInvalidScaleAnnotationMessage(autoscaling.MaxScaleAnnotationKey, "ten"),
+			LastTransitionTime: got.LastTransitionTime,
+			Severity:           "Error",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Unexpected revision conditions diff (-want +got): %v", diff)
+		}
+	}
+}
+
 // TODO(mattmoor): add coverage of a Reconcile fixing a stale logging URL
 func TestUpdateRevWithWithUpdatedLoggingURL(t *testing.T) {
 	controllerConfig := getTestControllerConfig()
@@ -18,7 +18,9 @@ package revision
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	caching "github.com/knative/caching/pkg/apis/caching/v1alpha1"
 	"github.com/knative/pkg/apis/duck"
@@ -26,6 +28,7 @@ import (
 	"github.com/knative/pkg/controller"
 	"github.com/knative/pkg/logging"
 	autoscalingv1alpha1 "github.com/knative/serving/pkg/apis/autoscaling/v1alpha1"
+	apisconfig "github.com/knative/serving/pkg/apis/config"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/knative/serving/pkg/autoscaler"
 	"github.com/knative/serving/pkg/reconciler"
@@ -40,6 +43,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 )
 
 // This is heavily based on the way the OpenShift Ingress controller tests its reconciliation method.
@@ -72,6 +76,11 @@ func TestReconcile(t *testing.T) {
 				// The first reconciliation Populates the following status properties.
 				WithK8sServiceName, WithLogURL, AllUnknownConditions),
 		}},
+		WantEvents: []string{
+			createdEvent("Deployment", "first-reconcile-deployment"),
+			createdEvent("Service", "first-reconcile-service"),
+			createdEvent("PodAutoscaler", "first-reconcile"),
+		},
 		Key: "foo/first-reconcile",
 	}, {
 		Name: "failure updating revision status",
@@ -97,10 +106,41 @@ func TestReconcile(t *testing.T) {
 				WithK8sServiceName, WithLogURL, AllUnknownConditions),
 		}},
 		WantEvents: []string{
+			createdEvent("Deployment", "update-status-failure-deployment"),
+			createdEvent("Service", "update-status-failure-service"),
 			Eventf(corev1.EventTypeWarning, "UpdateFailed", "Failed to update status for Revision %q: %v",
 				"update-status-failure", "inducing failure for update revisions"),
 		},
 		Key: "foo/update-status-failure",
+	}, {
+		Name: "conflict updating revision status",
+		// This starts from the first reconciliation case above, and has another
+		// actor update the revision before our first status update. We retry
+		// the status update instead of failing the reconcile.
+		WithReactors: []clientgotesting.ReactionFunc{
+			InduceConflictOnce("update", "revisions"),
+		},
+		Objects: []runtime.Object{
+			rev("foo", "update-status-conflict"),
+			kpa("foo", "update-status-conflict"),
+		},
+		WantCreates: []metav1.Object{
+			deploy("foo", "update-status-conflict"),
+			svc("foo", "update-status-conflict"),
+			image("foo", "update-status-conflict"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "update-status-conflict",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+		}, {
+			Object: rev("foo", "update-status-conflict",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+		}},
+		WantEvents: []string{
+			createdEvent("Deployment", "update-status-conflict-deployment"),
+			createdEvent("Service", "update-status-conflict-service"),
+		},
+		Key: "foo/update-status-conflict",
 	}, {
 		Name: "failure creating kpa",
 		// This starts from the first reconciliation case above and induces a failure
@@ -123,8 +163,15 @@ func TestReconcile(t *testing.T) {
 			Object: rev("foo", "create-kpa-failure",
 				// Despite failure, the following status properties are set.
 				WithK8sServiceName, WithLogURL, WithInitRevConditions,
-				WithNoBuild, MarkDeploying("Deploying")),
+				WithNoBuild, MarkDeploying("Deploying"),
+				MarkResourceFailed("PodAutoscaler", "create-kpa-failure",
+					"inducing failure for create podautoscalers")),
 		}},
+		WantEvents: []string{
+			createdEvent("Deployment", "create-kpa-failure-deployment"),
+			createdEvent("Service", "create-kpa-failure-service"),
+			failedEvent("create", "PodAutoscaler", "create-kpa-failure", "podautoscalers"),
+		},
 		Key: "foo/create-kpa-failure",
 	}, {
 		Name: "failure creating user deployment",
@@ -146,8 +193,13 @@ func TestReconcile(t *testing.T) {
 			Object: rev("foo", "create-user-deploy-failure",
 				// Despite failure, the following status properties are set.
 				WithLogURL, WithInitRevConditions,
-				WithNoBuild, MarkDeploying("Deploying")),
+				WithNoBuild, MarkDeploying("Deploying"),
+				MarkResourceFailed("Deployment", "create-user-deploy-failure-deployment",
+					"inducing failure for create deployments")),
 		}},
+		WantEvents: []string{
+			failedEvent("create", "Deployment", "create-user-deploy-failure-deployment", "deployments"),
+		},
 		Key: "foo/create-user-deploy-failure",
 	}, {
 		Name: "failure creating user service",
@@ -171,9 +223,68 @@ func TestReconcile(t *testing.T) {
 			Object: rev("foo", "create-user-service-failure",
 				// Despite failure, the following status properties are set.
 				WithK8sServiceName, WithLogURL, WithInitRevConditions,
-				WithNoBuild, MarkDeploying("Deploying")),
+				WithNoBuild, MarkDeploying("Deploying"),
+				MarkResourceFailed("Service", "create-user-service-failure-service",
+					"inducing failure for create services")),
 		}},
+		WantEvents: []string{
+			createdEvent("Deployment", "create-user-service-failure-deployment"),
+			failedEvent("create", "Service", "create-user-service-failure-service", "services"),
+		},
 		Key: "foo/create-user-service-failure",
+	}, {
+		Name: "deployment not owned",
+		// A Deployment with the name we would derive exists, but it is not
+		// controlled by this Revision; we must not adopt it.
+		WantErr: true,
+		Objects: []runtime.Object{
+			rev("foo", "deploy-not-owned"),
+			kpa("foo", "deploy-not-owned"),
+			noOwner(deploy("foo", "deploy-not-owned")),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "deploy-not-owned",
+				WithLogURL, WithInitRevConditions, WithNoBuild,
+				MarkResourceNotOwned("Deployment", "deploy-not-owned-deployment")),
+		}},
+		Key: "foo/deploy-not-owned",
+	}, {
+		Name: "service not owned",
+		// A Service with the name we would derive exists, but it is not
+		// controlled by this Revision; we must not adopt it.
+		WantErr: true,
+		Objects: []runtime.Object{
+			rev("foo", "svc-not-owned"),
+			kpa("foo", "svc-not-owned"),
+			deploy("foo", "svc-not-owned"),
+			noOwner(svc("foo", "svc-not-owned")),
+			image("foo", "svc-not-owned"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "svc-not-owned",
+				WithK8sServiceName, WithLogURL, WithInitRevConditions, WithNoBuild,
+				MarkResourceNotOwned("Service", "svc-not-owned-service")),
+		}},
+		Key: "foo/svc-not-owned",
+	}, {
+		Name: "kpa not owned",
+		// A PodAutoscaler with the name we would derive exists, but it is not
+		// controlled by this Revision; we must not adopt it.
+		WantErr: true,
+		Objects: []runtime.Object{
+			rev("foo", "kpa-not-owned",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+			noOwner(kpa("foo", "kpa-not-owned")),
+			deploy("foo", "kpa-not-owned"),
+			svc("foo", "kpa-not-owned"),
+			image("foo", "kpa-not-owned"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "kpa-not-owned",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkResourceNotOwned("PodAutoscaler", "kpa-not-owned")),
+		}},
+		Key: "foo/kpa-not-owned",
 	}, {
 		Name: "stable revision reconciliation",
 		// Test a simple stable reconciliation of an Active Revision.
@@ -206,6 +317,23 @@ func TestReconcile(t *testing.T) {
 			Object: deploy("foo", "fix-containers"),
 		}},
 		Key: "foo/fix-containers",
+	}, {
+		Name: "update deployment after sidecar image change",
+		// Test that changing the queue sidecar image in config-controller rolls
+		// out existing deployments: the sidecar images are part of the pod
+		// template, so the pod template differs and the deployment is updated.
+		Objects: []runtime.Object{
+			rev("foo", "new-sidecar",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+			kpa("foo", "new-sidecar"),
+			deploy("foo", "new-sidecar", oldQueueSidecarImage),
+			svc("foo", "new-sidecar"),
+			image("foo", "new-sidecar"),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: deploy("foo", "new-sidecar"),
+		}},
+		Key: "foo/new-sidecar",
 	}, {
 		Name: "failure updating deployment",
 		// Test that we handle an error updating the deployment properly.
@@ -224,7 +352,34 @@ func TestReconcile(t *testing.T) {
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: deploy("foo", "failure-update-deploy"),
 		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "failure-update-deploy",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkResourceFailed("Deployment", "failure-update-deploy-deployment",
+					"inducing failure for update deployments")),
+		}},
+		WantEvents: []string{
+			failedEvent("update", "Deployment", "failure-update-deploy-deployment", "deployments"),
+		},
 		Key: "foo/failure-update-deploy",
+	}, {
+		Name: "recover from failure creating user service",
+		// The children have been reconciled since the Service failed to be
+		// created, so the failure is cleared from the status.
+		Objects: []runtime.Object{
+			rev("foo", "recover-svc-failure",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkResourceFailed("Service", "recover-svc-failure-service", "exceeded quota")),
+			kpa("foo", "recover-svc-failure"),
+			deploy("foo", "recover-svc-failure"),
+			svc("foo", "recover-svc-failure"),
+			image("foo", "recover-svc-failure"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "recover-svc-failure",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+		}},
+		Key: "foo/recover-svc-failure",
 	}, {
 		Name: "deactivated revision is stable",
 		// Test a simple stable reconciliation of an inactive Revision.
@@ -241,11 +396,25 @@ func TestReconcile(t *testing.T) {
 			svc("foo", "stable-deactivation"),
 			image("foo", "stable-deactivation"),
 		},
-		WantEvents: []string{
-			Eventf(corev1.EventTypeNormal, "RevisionReady", "Revision becomes ready upon endpoint %q becoming ready",
-				"stable-deactivation-service"),
-		},
 		Key: "foo/stable-deactivation",
+	}, {
+		Name: "surface the service cluster ip",
+		// Test that the address assigned to the Service is reflected
+		// in the Revision's status.
+		Objects: []runtime.Object{
+			rev("foo", "cluster-ip",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+			kpa("foo", "cluster-ip"),
+			deploy("foo", "cluster-ip"),
+			svc("foo", "cluster-ip", WithClusterIP("10.0.0.12")),
+			image("foo", "cluster-ip"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "cluster-ip",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				WithRevisionClusterIP("10.0.0.12")),
+		}},
+		Key: "foo/cluster-ip",
 	}, {
 		Name: "endpoint is created (not ready)",
 		// Test the transition when a Revision's Endpoints are created (but not yet ready)
@@ -296,6 +465,21 @@ func TestReconcile(t *testing.T) {
 				"endpoint-created-timeout-service"),
 		},
 		Key: "foo/endpoint-created-timeout",
+	}, {
+		Name: "endpoint is created (already timed out)",
+		// Test that a Revision that already reflects the timeout doesn't emit
+		// another event on each reconcile.
+		Objects: []runtime.Object{
+			rev("foo", "endpoint-timed-out",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkActive, MarkServiceTimeout, WithEmptyLTTs),
+			kpa("foo", "endpoint-timed-out", WithTraffic),
+			deploy("foo", "endpoint-timed-out"),
+			svc("foo", "endpoint-timed-out"),
+			endpoints("foo", "endpoint-timed-out"),
+			image("foo", "endpoint-timed-out"),
+		},
+		Key: "foo/endpoint-timed-out",
 	}, {
 		Name: "endpoint and kpa are ready",
 		// Test the transition that Reconcile makes when Endpoints become ready.
@@ -342,10 +526,6 @@ func TestReconcile(t *testing.T) {
 				// state, we should see the following mutation.
 				MarkActivating("Something", "This is something longer")),
 		}},
-		WantEvents: []string{
-			Eventf(corev1.EventTypeNormal, "RevisionReady", "Revision becomes ready upon endpoint %q becoming ready",
-				"kpa-not-ready-service"),
-		},
 		Key: "foo/kpa-not-ready",
 	}, {
 		Name: "kpa inactive",
@@ -367,10 +547,6 @@ func TestReconcile(t *testing.T) {
 				// is inactive, we should see the following change.
 				MarkInactive("NoTraffic", "This thing is inactive.")),
 		}},
-		WantEvents: []string{
-			Eventf(corev1.EventTypeNormal, "RevisionReady", "Revision becomes ready upon endpoint %q becoming ready",
-				"kpa-inactive-service"),
-		},
 		Key: "foo/kpa-inactive",
 	}, {
 		Name: "mutated service gets fixed",
@@ -418,6 +594,15 @@ func TestReconcile(t *testing.T) {
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: svc("foo", "update-user-svc-failure"),
 		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "update-user-svc-failure",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkResourceFailed("Service", "update-user-svc-failure-service",
+					"inducing failure for update services")),
+		}},
+		WantEvents: []string{
+			failedEvent("update", "Service", "update-user-svc-failure-service", "services"),
+		},
 		Key: "foo/update-user-svc-failure",
 	}, {
 		Name: "surface deployment timeout",
@@ -532,6 +717,9 @@ func TestReconcile(t *testing.T) {
 		}},
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "BuildSucceeded", ""),
+			createdEvent("Deployment", "done-build-deployment"),
+			createdEvent("Service", "done-build-service"),
+			createdEvent("PodAutoscaler", "done-build"),
 		},
 		Key: "foo/done-build",
 	}, {
@@ -602,9 +790,12 @@ func TestReconcile(t *testing.T) {
 			serviceLister:       listers.GetK8sServiceLister(),
 			endpointsLister:     listers.GetEndpointsLister(),
 			configMapLister:     listers.GetConfigMapLister(),
+			nodeLister:          listers.GetNodeLister(),
 			resolver:            &nopResolver{},
 			tracker:             t,
 			configStore:         &testConfigStore{config: ReconcilerTestConfig()},
+			enqueueAfter:        func(interface{}, time.Duration) {},
+			numRequeues:         func(string) int { return 0 },
 
 			buildInformerFactory: newDuckInformerFactory(t, buildInformerFactory),
 		}
@@ -634,6 +825,11 @@ func TestReconcileWithVarLogEnabled(t *testing.T) {
 				// After the first reconciliation of a Revision the status looks like this.
 				WithK8sServiceName, WithLogURL, AllUnknownConditions),
 		}},
+		WantEvents: []string{
+			createdEvent("Deployment", "first-reconcile-var-log-deployment"),
+			createdEvent("Service", "first-reconcile-var-log-service"),
+			createdEvent("PodAutoscaler", "first-reconcile-var-log"),
+		},
 		Key: "foo/first-reconcile-var-log",
 	}, {
 		Name: "failure creating fluentd configmap",
@@ -657,8 +853,14 @@ func TestReconcileWithVarLogEnabled(t *testing.T) {
 				// the fluentd configmap, we should still see the following reflected
 				// in our status.
 				WithK8sServiceName, WithLogURL, WithInitRevConditions,
-				WithNoBuild, MarkDeploying("Deploying")),
+				WithNoBuild, MarkDeploying("Deploying"),
+				MarkResourceFailed("ConfigMap", "create-configmap-failure-fluentd",
+					"inducing failure for create configmaps")),
 		}},
+		WantEvents: []string{
+			createdEvent("Deployment", "create-configmap-failure-deployment"),
+			createdEvent("Service", "create-configmap-failure-service"),
+		},
 		Key: "foo/create-configmap-failure",
 	}, {
 		Name: "steady state after initial creation",
@@ -723,6 +925,12 @@ func TestReconcileWithVarLogEnabled(t *testing.T) {
 			// We should see a single update to the configmap we expect.
 			Object: fluentdConfigMap("foo", "update-configmap-failure", EnableVarLog),
 		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "update-configmap-failure",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkResourceFailed("ConfigMap", "update-configmap-failure-fluentd",
+					"inducing failure for update configmaps")),
+		}},
 		Key: "foo/update-configmap-failure",
 	}}
 
@@ -739,13 +947,419 @@ func TestReconcileWithVarLogEnabled(t *testing.T) {
 			serviceLister:       listers.GetK8sServiceLister(),
 			endpointsLister:     listers.GetEndpointsLister(),
 			configMapLister:     listers.GetConfigMapLister(),
+			nodeLister:          listers.GetNodeLister(),
 			resolver:            &nopResolver{},
 			tracker:             &rtesting.NullTracker{},
 			configStore:         &testConfigStore{config: config},
+			enqueueAfter:        func(interface{}, time.Duration) {},
+			numRequeues:         func(string) int { return 0 },
 		}
 	}))
 }
 
+func TestReconcileWithServiceBeforeDeployment(t *testing.T) {
+	table := TableTest{{
+		Name: "first revision reconciliation (service first)",
+		// Test the first reconciliation when the Service is configured to be
+		// created before the Deployment. This is the same as "first-reconcile",
+		// but the Service create must precede the Deployment create.
+		Objects: []runtime.Object{
+			rev("foo", "first-reconcile-svc-first"),
+		},
+		WantCreates: []metav1.Object{
+			kpa("foo", "first-reconcile-svc-first"),
+			svc("foo", "first-reconcile-svc-first"),
+			deploy("foo", "first-reconcile-svc-first"),
+			image("foo", "first-reconcile-svc-first"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "first-reconcile-svc-first",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+		}},
+		WantEvents: []string{
+			createdEvent("Service", "first-reconcile-svc-first-service"),
+			createdEvent("Deployment", "first-reconcile-svc-first-deployment"),
+			createdEvent("PodAutoscaler", "first-reconcile-svc-first"),
+		},
+		Key: "foo/first-reconcile-svc-first",
+	}, {
+		Name: "failure creating user service (service first)",
+		// When the Service is created first, a failure creating it must
+		// keep us from creating the Deployment.
+		WantErr: true,
+		WithReactors: []clientgotesting.ReactionFunc{
+			InduceFailure("create", "services"),
+		},
+		Objects: []runtime.Object{
+			rev("foo", "create-svc-first-failure"),
+			kpa("foo", "create-svc-first-failure"),
+		},
+		WantCreates: []metav1.Object{
+			svc("foo", "create-svc-first-failure"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "create-svc-first-failure",
+				WithK8sServiceName, WithLogURL, WithInitRevConditions,
+				WithNoBuild, MarkDeploying("Deploying"),
+				MarkResourceFailed("Service", "create-svc-first-failure-service",
+					"inducing failure for create services")),
+		}},
+		WantEvents: []string{
+			failedEvent("create", "Service", "create-svc-first-failure-service", "services"),
+		},
+		Key: "foo/create-svc-first-failure",
+	}}
+
+	config := ReconcilerTestConfig()
+	config.Controller.CreateServiceBeforeDeployment = true
+
+	table.Test(t, MakeFactory(func(listers *Listers, opt reconciler.Options) controller.Reconciler {
+		return &Reconciler{
+			Base:                reconciler.NewBase(opt, controllerAgentName),
+			revisionLister:      listers.GetRevisionLister(),
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			imageLister:         listers.GetImageLister(),
+			deploymentLister:    listers.GetDeploymentLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			endpointsLister:     listers.GetEndpointsLister(),
+			configMapLister:     listers.GetConfigMapLister(),
+			nodeLister:          listers.GetNodeLister(),
+			resolver:            &nopResolver{},
+			tracker:             &rtesting.NullTracker{},
+			configStore:         &testConfigStore{config: config},
+			enqueueAfter:        func(interface{}, time.Duration) {},
+			numRequeues:         func(string) int { return 0 },
+		}
+	}))
+}
+
+func TestReconcileWithImagePullFailure(t *testing.T) {
+	var (
+		gotKey   string
+		gotDelay time.Duration
+	)
+
+	table := TableTest{{
+		Name: "image pull errors already surfaced",
+		// Test that an image pull failure the revision already reflects
+		// doesn't emit another event on each reconcile.
+		Objects: []runtime.Object{
+			rev("foo", "pull-error-surfaced",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions, MarkActive,
+				MarkImagePullFailed("Back-off pulling image")),
+			kpa("foo", "pull-error-surfaced", WithTraffic),
+			pod("foo", "pull-error-surfaced", WithWaitingContainer("user-container", "ImagePullBackOff", "Back-off pulling image"),
+				func(p *corev1.Pod) { p.Status.ContainerStatuses[0].Image = "busybox" }),
+			deploy("foo", "pull-error-surfaced"),
+			svc("foo", "pull-error-surfaced"),
+			endpoints("foo", "pull-error-surfaced"),
+			image("foo", "pull-error-surfaced"),
+		},
+		Key: "foo/pull-error-surfaced",
+	}, {
+		Name: "surface image pull errors",
+		// Test the propagation of an image pull failure of a Pod into the
+		// revision, and that the revision is checked on again after the
+		// configured delay.
+		Objects: []runtime.Object{
+			rev("foo", "pull-error",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions, MarkActive),
+			kpa("foo", "pull-error", WithTraffic),
+			pod("foo", "pull-error", WithWaitingContainer("user-container", "ImagePullBackOff", "Back-off pulling image"),
+				func(p *corev1.Pod) { p.Status.ContainerStatuses[0].Image = "busybox" }),
+			deploy("foo", "pull-error"),
+			svc("foo", "pull-error"),
+			endpoints("foo", "pull-error"),
+			image("foo", "pull-error"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "pull-error",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions, MarkActive,
+				MarkImagePullFailed("Back-off pulling image")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "ImagePullFailed", "Failed to pull image %q: %s",
+				"busybox", "Back-off pulling image"),
+		},
+		Key: "foo/pull-error",
+	}}
+
+	config := ReconcilerTestConfig()
+	config.Controller.ImagePullRetryPeriod = 5 * time.Second
+
+	table.Test(t, MakeFactory(func(listers *Listers, opt reconciler.Options) controller.Reconciler {
+		return &Reconciler{
+			Base:                reconciler.NewBase(opt, controllerAgentName),
+			revisionLister:      listers.GetRevisionLister(),
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			imageLister:         listers.GetImageLister(),
+			deploymentLister:    listers.GetDeploymentLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			endpointsLister:     listers.GetEndpointsLister(),
+			configMapLister:     listers.GetConfigMapLister(),
+			nodeLister:          listers.GetNodeLister(),
+			resolver:            &nopResolver{},
+			tracker:             &rtesting.NullTracker{},
+			configStore:         &testConfigStore{config: config},
+			enqueueAfter: func(obj interface{}, after time.Duration) {
+				gotKey, _ = cache.MetaNamespaceKeyFunc(obj)
+				gotDelay = after
+			},
+			numRequeues: func(string) int { return 0 },
+		}
+	}))
+
+	if got, want := gotKey, "foo/pull-error"; got != want {
+		t.Errorf("enqueueAfter key = %q, want %q", got, want)
+	}
+	if got, want := gotDelay, 5*time.Second; got != want {
+		t.Errorf("enqueueAfter delay = %v, want %v", got, want)
+	}
+}
+
+func TestReconcileWithImageSizeThreshold(t *testing.T) {
+	const digest = "gcr.io/repo/image@sha256:deadbeef"
+
+	table := TableTest{{
+		Name: "image larger than the threshold",
+		// Test that an image larger than the configured threshold, as reported
+		// by the node running the Revision's pod, is surfaced with an event.
+		Objects: []runtime.Object{
+			rev("foo", "big-image",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+			kpa("foo", "big-image"),
+			availableDeploy(deploy("foo", "big-image")),
+			svc("foo", "big-image"),
+			image("foo", "big-image"),
+			pod("foo", "big-image", WithRunningContainer("user-container", "node-1", "busybox", "docker-pullable://"+digest)),
+			node("node-1", digest, 2000),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "big-image",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkImageSizeExceeded("busybox", 2000, 1000)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "ImageSizeExceeded",
+				"Image %q is %d bytes, which exceeds the threshold of %d bytes", "busybox", 2000, 1000),
+		},
+		Key: "foo/big-image",
+	}, {
+		Name: "image larger than the threshold already surfaced",
+		// Test that the event is only emitted when the image newly exceeds
+		// the threshold, not on every reconcile.
+		Objects: []runtime.Object{
+			rev("foo", "big-image-surfaced",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkImageSizeExceeded("busybox", 2000, 1000)),
+			kpa("foo", "big-image-surfaced"),
+			availableDeploy(deploy("foo", "big-image-surfaced")),
+			svc("foo", "big-image-surfaced"),
+			image("foo", "big-image-surfaced"),
+			pod("foo", "big-image-surfaced", WithRunningContainer("user-container", "node-1", "busybox", "docker-pullable://"+digest)),
+			node("node-1", digest, 2000),
+		},
+		Key: "foo/big-image-surfaced",
+	}, {
+		Name: "image smaller than the threshold",
+		Objects: []runtime.Object{
+			rev("foo", "small-image",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+			kpa("foo", "small-image"),
+			availableDeploy(deploy("foo", "small-image")),
+			svc("foo", "small-image"),
+			image("foo", "small-image"),
+			pod("foo", "small-image", WithRunningContainer("user-container", "node-1", "busybox", "docker-pullable://"+digest)),
+			node("node-1", digest, 500),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "small-image",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions,
+				MarkImageSizeWithinThreshold),
+		}},
+		Key: "foo/small-image",
+	}, {
+		Name: "ready revision is not checked",
+		// Test that the image of a Revision that is already ready isn't
+		// looked up again.
+		Objects: []runtime.Object{
+			rev("foo", "ready-big-image",
+				WithK8sServiceName, WithLogURL, MarkRevisionReady),
+			kpa("foo", "ready-big-image", WithTraffic),
+			availableDeploy(deploy("foo", "ready-big-image")),
+			svc("foo", "ready-big-image"),
+			endpoints("foo", "ready-big-image", WithSubsets),
+			image("foo", "ready-big-image"),
+			pod("foo", "ready-big-image", WithRunningContainer("user-container", "node-1", "busybox", "docker-pullable://"+digest)),
+			node("node-1", digest, 2000),
+		},
+		Key: "foo/ready-big-image",
+	}}
+
+	config := ReconcilerTestConfig()
+	config.Controller.ImageSizeThresholdBytes = 1000
+
+	table.Test(t, MakeFactory(func(listers *Listers, opt reconciler.Options) controller.Reconciler {
+		return &Reconciler{
+			Base:                reconciler.NewBase(opt, controllerAgentName),
+			revisionLister:      listers.GetRevisionLister(),
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			imageLister:         listers.GetImageLister(),
+			deploymentLister:    listers.GetDeploymentLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			endpointsLister:     listers.GetEndpointsLister(),
+			configMapLister:     listers.GetConfigMapLister(),
+			nodeLister:          listers.GetNodeLister(),
+			resolver:            &nopResolver{},
+			tracker:             &rtesting.NullTracker{},
+			configStore:         &testConfigStore{config: config},
+			enqueueAfter:        func(interface{}, time.Duration) {},
+			numRequeues:         func(string) int { return 0 },
+		}
+	}))
+}
+
+func TestReconcileWithMinReadySeconds(t *testing.T) {
+	table := TableTest{{
+		Name: "endpoint ready, pod not yet available",
+		// The pod is ready, but hasn't been for minReadySeconds yet, so the
+		// Revision isn't Ready either.
+		Objects: []runtime.Object{
+			rev("foo", "not-available",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+			kpa("foo", "not-available", WithTraffic),
+			deploy("foo", "not-available", withMinReadySeconds),
+			svc("foo", "not-available"),
+			endpoints("foo", "not-available", WithSubsets),
+			image("foo", "not-available"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "not-available",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions, MarkActive),
+		}},
+		Key: "foo/not-available",
+	}, {
+		Name: "endpoint ready, pod available",
+		Objects: []runtime.Object{
+			rev("foo", "available",
+				WithK8sServiceName, WithLogURL, AllUnknownConditions),
+			kpa("foo", "available", WithTraffic),
+			availableDeploy(deploy("foo", "available", withMinReadySeconds)),
+			svc("foo", "available"),
+			endpoints("foo", "available", WithSubsets),
+			image("foo", "available"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "available", WithK8sServiceName, WithLogURL,
+				MarkRevisionReady),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "RevisionReady", "Revision becomes ready upon endpoint %q becoming ready",
+				"available-service"),
+		},
+		Key: "foo/available",
+	}}
+
+	config := ReconcilerTestConfig()
+	withMinReadySeconds(config)
+
+	table.Test(t, MakeFactory(func(listers *Listers, opt reconciler.Options) controller.Reconciler {
+		return &Reconciler{
+			Base:                reconciler.NewBase(opt, controllerAgentName),
+			revisionLister:      listers.GetRevisionLister(),
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			imageLister:         listers.GetImageLister(),
+			deploymentLister:    listers.GetDeploymentLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			endpointsLister:     listers.GetEndpointsLister(),
+			configMapLister:     listers.GetConfigMapLister(),
+			nodeLister:          listers.GetNodeLister(),
+			resolver:            &nopResolver{},
+			tracker:             &rtesting.NullTracker{},
+			configStore:         &testConfigStore{config: config},
+			enqueueAfter:        func(interface{}, time.Duration) {},
+			numRequeues:         func(string) int { return 0 },
+		}
+	}))
+}
+
+func TestReconcileGivesUpAfterRetries(t *testing.T) {
+	table := TableTest{{
+		Name: "failure creating user deployment after max retries",
+		// This starts from the "failure creating user deployment" case above,
+		// but the Revision has already been retried the maximum number of
+		// times, so the error is swallowed and the key forgotten.
+		WithReactors: []clientgotesting.ReactionFunc{
+			InduceFailure("create", "deployments"),
+		},
+		Objects: []runtime.Object{
+			rev("foo", "retries-exhausted"),
+			kpa("foo", "retries-exhausted"),
+		},
+		WantCreates: []metav1.Object{
+			deploy("foo", "retries-exhausted"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "retries-exhausted",
+				WithLogURL, WithInitRevConditions,
+				WithNoBuild, MarkDeploying("Deploying"),
+				MarkResourceFailed("Deployment", "retries-exhausted-deployment",
+					"inducing failure for create deployments")),
+		}},
+		WantEvents: []string{
+			failedEvent("create", "Deployment", "retries-exhausted-deployment", "deployments"),
+			Eventf(corev1.EventTypeWarning, "ReconcileRetriesExhausted", "Giving up on Revision %q after %d retries: %v",
+				"retries-exhausted", config.DefaultMaxReconcileRetries, "inducing failure for create deployments"),
+		},
+		Key: "foo/retries-exhausted",
+	}}
+
+	table.Test(t, MakeFactory(func(listers *Listers, opt reconciler.Options) controller.Reconciler {
+		return &Reconciler{
+			Base:                reconciler.NewBase(opt, controllerAgentName),
+			revisionLister:      listers.GetRevisionLister(),
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			imageLister:         listers.GetImageLister(),
+			deploymentLister:    listers.GetDeploymentLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			endpointsLister:     listers.GetEndpointsLister(),
+			configMapLister:     listers.GetConfigMapLister(),
+			nodeLister:          listers.GetNodeLister(),
+			resolver:            &nopResolver{},
+			tracker:             &rtesting.NullTracker{},
+			configStore:         &testConfigStore{config: ReconcilerTestConfig()},
+			enqueueAfter:        func(interface{}, time.Duration) {},
+			numRequeues:         func(string) int { return config.DefaultMaxReconcileRetries },
+		}
+	}))
+}
+
+func availableDeploy(deploy *appsv1.Deployment) *appsv1.Deployment {
+	deploy.Status.AvailableReplicas = 1
+	return deploy
+}
+
+func node(name, image string, size int64) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NodeStatus{
+			Images: []corev1.ContainerImage{{
+				Names:     []string{image},
+				SizeBytes: size,
+			}},
+		},
+	}
+}
+
+// noOwner strips the owner references from the given object, so that it
+// is no longer controlled by the Revision it was generated from.
+func noOwner(obj runtime.Object) runtime.Object {
+	obj.(metav1.Object).SetOwnerReferences(nil)
+	return obj
+}
+
 func timeoutDeploy(deploy *appsv1.Deployment) *appsv1.Deployment {
 	deploy.Status.Conditions = []appsv1.DeploymentCondition{{
 		Type:   appsv1.DeploymentProgressing,
@@ -805,6 +1419,12 @@ func WithK8sServiceName(r *v1alpha1.Revision) {
 	r.Status.ServiceName = svc(r.Namespace, r.Name).Name
 }
 
+func WithRevisionClusterIP(ip string) RevisionOption {
+	return func(r *v1alpha1.Revision) {
+		r.Status.ClusterIP = ip
+	}
+}
+
 // TODO(mattmoor): Come up with a better name for this.
 func AllUnknownConditions(r *v1alpha1.Revision) {
 	WithInitRevConditions(r)
@@ -927,7 +1547,14 @@ var _ configStore = (*testConfigStore)(nil)
 func ReconcilerTestConfig() *config.Config {
 	return &config.Config{
 		Controller: getTestControllerConfig(),
-		Network:    &config.Network{IstioOutboundIPRanges: "*"},
+		Network: &config.Network{
+			IstioOutboundIPRanges: "*",
+			QueueProxyPorts: apisconfig.QueueProxyPorts{
+				Serving: v1alpha1.RequestQueuePort,
+				Admin:   v1alpha1.RequestQueueAdminPort,
+				Metrics: v1alpha1.RequestQueueMetricsPort,
+			},
+		},
 		Observability: &config.Observability{
 			LoggingURLTemplate: "http://logger.io/${REVISION_UID}",
 		},
@@ -939,3 +1566,20 @@ func ReconcilerTestConfig() *config.Config {
 func EnableVarLog(cfg *config.Config) {
 	cfg.Observability.EnableVarLogCollection = true
 }
+
+func withMinReadySeconds(cfg *config.Config) {
+	cfg.Controller.MinReadySeconds = 10
+}
+
+func oldQueueSidecarImage(cfg *config.Config) {
+	cfg.Controller.QueueSidecarImage = "queue-proxy:old"
+}
+
+func createdEvent(kind, name string) string {
+	return Eventf(corev1.EventTypeNormal, "Created"+kind, "Created %s %q", kind, name)
+}
+
+func failedEvent(verb, kind, name, resource string) string {
+	return Eventf(corev1.EventTypeWarning, "Failed"+strings.Title(verb)+kind, "Failed to %s %s %q: %v",
+		verb, kind, name, "inducing failure for "+verb+" "+resource)
+}
@@ -34,6 +34,7 @@ type (
 
 var (
 	InduceFailure             = testing.InduceFailure
+	InduceConflictOnce        = testing.InduceConflictOnce
 	KeyOrDie                  = testing.KeyOrDie
 	NewHooks                  = testing.NewHooks
 	ExpectNormalEventDelivery = testing.ExpectNormalEventDelivery
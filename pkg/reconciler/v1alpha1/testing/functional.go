@@ -600,6 +600,27 @@ func MarkContainerMissing(rev *v1alpha1.Revision) {
 	rev.Status.MarkContainerMissing("It's the end of the world as we know it")
 }
 
+// MarkResourceNotOwned calls .Status.MarkResourceNotOwned on the Revision.
+func MarkResourceNotOwned(kind, name string) RevisionOption {
+	return func(rev *v1alpha1.Revision) {
+		rev.Status.MarkResourceNotOwned(kind, name)
+	}
+}
+
+// MarkResourceFailed calls .Status.MarkResourceFailed on the Revision.
+func MarkResourceFailed(kind, name, message string) RevisionOption {
+	return func(rev *v1alpha1.Revision) {
+		rev.Status.MarkResourceFailed(kind, name, message)
+	}
+}
+
+// MarkInvalidScaleAnnotation calls .Status.MarkInvalidScaleAnnotation on the Revision.
+func MarkInvalidScaleAnnotation(key, value string) RevisionOption {
+	return func(rev *v1alpha1.Revision) {
+		rev.Status.MarkInvalidScaleAnnotation(key, value)
+	}
+}
+
 // MarkContainerExiting calls .Status.MarkContainerExiting on the Revision.
 func MarkContainerExiting(exitCode int32, message string) RevisionOption {
 	return func(r *v1alpha1.Revision) {
@@ -607,6 +628,25 @@ func MarkContainerExiting(exitCode int32, message string) RevisionOption {
 	}
 }
 
+// MarkImagePullFailed calls .Status.MarkImagePullFailed on the Revision.
+func MarkImagePullFailed(message string) RevisionOption {
+	return func(r *v1alpha1.Revision) {
+		r.Status.MarkImagePullFailed(message)
+	}
+}
+
+// MarkImageSizeExceeded calls .Status.MarkImageSizeExceeded on the Revision.
+func MarkImageSizeExceeded(image string, size, threshold int64) RevisionOption {
+	return func(r *v1alpha1.Revision) {
+		r.Status.MarkImageSizeExceeded(image, size, threshold)
+	}
+}
+
+// MarkImageSizeWithinThreshold calls .Status.MarkImageSizeWithinThreshold on the Revision.
+func MarkImageSizeWithinThreshold(r *v1alpha1.Revision) {
+	r.Status.MarkImageSizeWithinThreshold()
+}
+
 // MarkRevisionReady calls the necessary helpers to make the Revision Ready=True.
 func MarkRevisionReady(r *v1alpha1.Revision) {
 	WithInitRevConditions(r)
@@ -675,6 +715,17 @@ func WithTargetAnnotation(target string) PodAutoscalerOption {
 	}
 }
 
+// WithTickIntervalAnnotation sets the autoscaling.knative.dev/tickInterval
+// annotation of the PodAutoscaler to the provided value.
+func WithTickIntervalAnnotation(tickInterval string) PodAutoscalerOption {
+	return func(pa *autoscalingv1alpha1.PodAutoscaler) {
+		if pa.Annotations == nil {
+			pa.Annotations = make(map[string]string)
+		}
+		pa.Annotations[autoscaling.TickIntervalAnnotationKey] = tickInterval
+	}
+}
+
 // WithMetricAnnotation adds a metric annotation to the PA.
 func WithMetricAnnotation(metric string) PodAutoscalerOption {
 	return func(pa *autoscalingv1alpha1.PodAutoscaler) {
@@ -736,3 +787,40 @@ func WithFailingContainer(name string, exitCode int, message string) PodOption {
 		}
 	}
 }
+
+// WithWaitingContainer sets the .Status.ContainerStatuses on the pod to
+// include a container named accordingly that is waiting for the given reason.
+func WithWaitingContainer(name, reason, message string) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{
+				Name: name,
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{
+						Reason:  reason,
+						Message: message,
+					},
+				},
+			},
+		}
+	}
+}
+
+// WithRunningContainer sets the .Status.ContainerStatuses on the pod to
+// include a container named accordingly running the given image, and
+// schedules the pod on the given node.
+func WithRunningContainer(name, nodeName, image, imageID string) PodOption {
+	return func(pod *corev1.Pod) {
+		pod.Spec.NodeName = nodeName
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{
+				Name:    name,
+				Image:   image,
+				ImageID: imageID,
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+			},
+		}
+	}
+}
@@ -152,3 +152,7 @@ func (l *Listers) GetEndpointsLister() corev1listers.EndpointsLister {
 func (l *Listers) GetConfigMapLister() corev1listers.ConfigMapLister {
 	return corev1listers.NewConfigMapLister(l.indexerFor(&corev1.ConfigMap{}))
 }
+
+func (l *Listers) GetNodeLister() corev1listers.NodeLister {
+	return corev1listers.NewNodeLister(l.indexerFor(&corev1.Node{}))
+}
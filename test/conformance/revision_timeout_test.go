@@ -203,10 +203,10 @@ func TestRevisionTimeout(t *testing.T) {
 	}
 
 	// Fail by surpassing the initial timeout.
-	if err := sendRequest(logger, clients, rev2sDomain, 5, 0, http.StatusServiceUnavailable); err != nil {
+	if err := sendRequest(logger, clients, rev2sDomain, 5, 0, http.StatusGatewayTimeout); err != nil {
 		t.Errorf("Did not fail request with sleep 5s with revision timeout 2s: %v", err)
 	}
-	if err := sendRequest(logger, clients, rev5sDomain, 7, 0, http.StatusServiceUnavailable); err != nil {
+	if err := sendRequest(logger, clients, rev5sDomain, 7, 0, http.StatusGatewayTimeout); err != nil {
 		t.Errorf("Did not fail request with sleep 7s with revision timeout 5s: %v", err)
 	}
 